@@ -0,0 +1,135 @@
+package plain
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// MarshalAligned is like Marshal but pads keys so the values of each record
+// start in the same column. A width above 0 wraps values longer than width
+// columns at spaces, ending each wrapped line with a space and a backslash
+// and indenting the next to the value column. Unmarshal joins the lines
+// back into the value.
+func MarshalAligned(v any, width int) ([]byte, error) {
+	return newEncodeState(encodeOptions{align: true, wrap: width}).marshal(v)
+}
+
+// wrapValue breaks a value at spaces so lines written after a prefix of
+// indent columns fit the wrap width. Values spanning lines and dotenv values
+// are not wrapped.
+func (e *encodeState) wrapValue(value string, indent int) string {
+	if e.wrap <= 0 || e.dialect.Syntax == SyntaxDotenv || strings.Contains(value, "\n") {
+		return value
+	}
+
+	available := e.wrap - indent
+	var lines []string
+	for utf8.RuneCountInString(value) > available {
+		// Leave room for the backslash ending a wrapped line
+		end := breakAt(value, available-1)
+		if end < 0 {
+			break
+		}
+
+		lines = append(lines, value[:end])
+		value = value[end:]
+	}
+	if len(lines) == 0 {
+		return value
+	}
+
+	lines = append(lines, value)
+	return strings.Join(lines, "\\\n"+strings.Repeat(" ", indent))
+}
+
+// breakAt returns the byte index after the last run of spaces that ends
+// within limit columns, or after the first run if none does. Breaking there
+// leaves the spaces at the end of the line, where decoding keeps them. It
+// returns -1 when value has no spaces to break at.
+func breakAt(value string, limit int) int {
+	best := -1
+	for i := 1; i < len(value); i++ {
+		if value[i] != ' ' || value[i-1] == ' ' {
+			continue
+		}
+
+		end := i
+		for end < len(value) && value[end] == ' ' {
+			end++
+		}
+		if end == len(value) {
+			break // nothing left to put on the next line
+		}
+		if best >= 0 && utf8.RuneCountInString(value[:end]) > limit {
+			break
+		}
+
+		best = end
+	}
+
+	return best
+}
+
+// trailingBackslashes returns the number of backslashes ending a line when
+// they follow a space or tab, the only place where they are special. An odd
+// number continues the line, the last backslash being the marker, and the
+// others stand for half as many literal backslashes.
+func trailingBackslashes(line string) int {
+	n := len(line) - len(strings.TrimRight(line, "\\"))
+	if n == 0 || n == len(line) {
+		return 0
+	}
+	if c := line[len(line)-n-1]; c != ' ' && c != '\t' {
+		return 0
+	}
+
+	return n
+}
+
+// isContinued checks if a line ends in a space or tab followed by an odd
+// number of backslashes, which continues its value on the next line
+func isContinued(line string) bool {
+	return trailingBackslashes(strings.TrimRight(line, "\r"))%2 == 1
+}
+
+// escapeContinued doubles the backslashes ending a line, so a value ending
+// in a space and a backslash is not read as continued
+func escapeContinued(line string) string {
+	return line + strings.Repeat("\\", trailingBackslashes(line))
+}
+
+// continuedLines joins continued lines with the line that follows, dropping
+// the backslash and the leading whitespace of the next line, and unescapes
+// the backslashes ending a line. Each logical line keeps the number of its
+// first physical line.
+func continuedLines(lines []sourceLine) []sourceLine {
+	var joined []sourceLine
+	for i := 0; i < len(lines); i++ {
+		current := lines[i]
+		if isComment(strings.TrimSpace(current.text)) {
+			joined = append(joined, current)
+			continue
+		}
+
+		text := current.text
+		for {
+			trimmed := strings.TrimRight(text, "\r")
+			n := trailingBackslashes(trimmed)
+			if n == 0 {
+				break
+			}
+			if n%2 == 0 || i+1 == len(lines) {
+				// A last line ending in a marker keeps it
+				text = trimmed[:len(trimmed)-n/2]
+				break
+			}
+
+			i++
+			text = trimmed[:len(trimmed)-n+n/2] + strings.TrimLeft(lines[i].text, " \t")
+		}
+
+		joined = append(joined, sourceLine{text: text, line: current.line})
+	}
+
+	return joined
+}
@@ -0,0 +1,158 @@
+package plain
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type TestAligned struct {
+	Name        string   `plain:"name"`
+	Description string   `plain:"description"`
+	Tags        []string `plain:"tags"`
+}
+
+func TestMarshalAligned(t *testing.T) {
+	t.Run("Align", func(t *testing.T) {
+		resp, err := MarshalAligned([]TestAligned{{Name: "a", Description: "short"}, {Name: "b"}}, 0)
+		if err != nil {
+			t.Fatalf("Was not expecting an error got %s", err)
+		}
+
+		expected := "name:        a\ndescription: short\ntags:        []\n\nname:        b\ndescription: \ntags:        []"
+		if string(resp) != expected {
+			t.Fatalf("MarshalAligned was expecting %q\n got %q", expected, resp)
+		}
+	})
+
+	t.Run("Wrap", func(t *testing.T) {
+		value := TestAligned{
+			Name:        "wrapped",
+			Description: "the quick brown fox jumps over  the lazy dog",
+			Tags:        []string{"alpha", "beta", "gamma", "delta"},
+		}
+		resp, err := MarshalAligned(value, 30)
+		if err != nil {
+			t.Fatalf("Was not expecting an error got %s", err)
+		}
+
+		expected := strings.Join([]string{
+			"name:        wrapped",
+			"description: the quick brown \\",
+			"             fox jumps over  \\",
+			"             the lazy dog",
+			"tags:        [alpha, beta, \\",
+			"             gamma, delta]",
+		}, "\n")
+		if string(resp) != expected {
+			t.Fatalf("MarshalAligned was expecting %q\n got %q", expected, resp)
+		}
+
+		var result TestAligned
+		if err := Unmarshal(resp, &result); err != nil {
+			t.Fatalf("Was not expecting an error got %s", err)
+		}
+		if !reflect.DeepEqual(result, value) {
+			t.Fatalf("Round trip was expecting %+v\n got %+v", value, result)
+		}
+	})
+
+	t.Run("Long word", func(t *testing.T) {
+		resp, err := MarshalAligned(TestAligned{Name: strings.Repeat("x", 40) + " end"}, 20)
+		if err != nil {
+			t.Fatalf("Was not expecting an error got %s", err)
+		}
+
+		expected := "name:        " + strings.Repeat("x", 40) + " \\\n             end"
+		if !strings.HasPrefix(string(resp), expected) {
+			t.Fatalf("MarshalAligned was expecting %q\n got %q", expected, resp)
+		}
+	})
+
+	t.Run("Properties", func(t *testing.T) {
+		var buf bytes.Buffer
+		enc := NewEncoder(&buf)
+		enc.SetDialect(DialectProperties)
+		enc.SetAlign(true)
+		enc.SetWrap(24)
+		value := TestAligned{Name: "p", Description: "one two three four five"}
+		if err := enc.Encode(value); err != nil {
+			t.Fatalf("Was not expecting an error got %s", err)
+		}
+
+		expected := "name=       p\ndescription=one two \\\n            three four \\\n            five\ntags=       \n"
+		if buf.String() != expected {
+			t.Fatalf("Encoder was expecting %q\n got %q", expected, buf.String())
+		}
+
+		var result TestAligned
+		if err := UnmarshalDialect(buf.Bytes(), &result, DialectProperties); err != nil {
+			t.Fatalf("Was not expecting an error got %s", err)
+		}
+		if result.Description != value.Description {
+			t.Fatalf("Round trip was expecting %q got %q", value.Description, result.Description)
+		}
+	})
+}
+
+func TestContinuedLines(t *testing.T) {
+	t.Run("Unmarshal", func(t *testing.T) {
+		var result TestAligned
+		data := "name: a \\\n   b\n# comment \\\ndescription: C:\\dir\\\n"
+		if err := Unmarshal([]byte(data), &result); err != nil {
+			t.Fatalf("Was not expecting an error got %s", err)
+		}
+		if result.Name != "a b" || result.Description != `C:\dir\` {
+			t.Fatalf("Unmarshal was expecting continued lines joined got %+v", result)
+		}
+	})
+
+	t.Run("Parse", func(t *testing.T) {
+		data := "name: a \\\n  b\nage: 3\n"
+		doc, err := Parse([]byte(data))
+		if err != nil {
+			t.Fatalf("Was not expecting an error got %s", err)
+		}
+
+		if value, _ := doc.Get("name"); value != "a b" {
+			t.Fatalf("Parse was expecting the joined value got %q", value)
+		}
+		if n := doc.Nodes()[1]; n.Key != "age" || n.Line != 3 {
+			t.Fatalf("Parse was expecting age on line 3 got %s on %d", n.Key, n.Line)
+		}
+		if string(doc.Bytes()) != data {
+			t.Fatalf("Parse was expecting the source kept got %q", doc.Bytes())
+		}
+	})
+	t.Run("Trailing backslash", func(t *testing.T) {
+		for _, name := range []string{`dir \`, `dir \\`, `\`, `a \ b \`} {
+			value := TestAligned{Name: name, Description: "next"}
+			for _, marshal := range []func(any) ([]byte, error){Marshal, func(v any) ([]byte, error) { return MarshalAligned(v, 6) }} {
+				data, err := marshal(value)
+				if err != nil {
+					t.Fatalf("Was not expecting an error got %s", err)
+				}
+
+				var result TestAligned
+				if err := Unmarshal(data, &result); err != nil {
+					t.Fatalf("Was not expecting an error got %s", err)
+				}
+				if !reflect.DeepEqual(result, value) {
+					t.Fatalf("Round trip of %q was expecting %+v\n got %+v", data, value, result)
+				}
+			}
+		}
+	})
+
+	t.Run("Format trailing backslash", func(t *testing.T) {
+		data := "name: dir \\\\\ndescription: next\n"
+		resp, err := Format([]byte(data))
+		if err != nil {
+			t.Fatalf("Was not expecting an error got %s", err)
+		}
+		if string(resp) != data {
+			t.Fatalf("Format was expecting %q\n got %q", data, resp)
+		}
+	})
+}
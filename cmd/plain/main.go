@@ -0,0 +1,320 @@
+// Command plain converts between plain records and JSON.
+//
+// Usage:
+//
+//	plain <command> [file ...]
+//	plain query <expression> [file ...]
+//
+// The commands are:
+//
+//	tojson    convert plain records to JSON
+//	fromjson  convert JSON objects to plain records
+//	fmt       rewrite plain records in canonical form
+//	validate  check that plain records can be decoded
+//	query     print the values matching a query expression
+//
+// The query command takes the expression as its first argument, see
+// plain.Query for the syntax.
+//
+// The fmt command accepts -align to line up the values of each record and
+// -sort to order the keys of each record.
+//
+// Input is read from the named files, or from stdin when none are given, and
+// output is written to stdout.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/brianvoe/plain"
+)
+
+const usage = `usage: plain <command> [file ...]
+       plain query <expression> [file ...]
+
+commands:
+  tojson    convert plain records to JSON
+  fromjson  convert JSON objects to plain records
+  fmt       rewrite plain records in canonical form
+  validate  check that plain records can be decoded
+  query     print the values matching a query expression
+
+fmt flags:
+  -align    line up the values of each record
+  -sort     order the keys of each record
+`
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// input is the content of one file, or of stdin
+type input struct {
+	name string
+	data []byte
+}
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+
+	command := args[0]
+	flags := flag.NewFlagSet("plain "+command, flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() { fmt.Fprint(stderr, usage) }
+
+	var cmd func(inputs []input, stdout io.Writer) error
+	switch command {
+	case "tojson":
+		cmd = toJSON
+	case "fromjson":
+		cmd = fromJSON
+	case "fmt":
+		var opts plain.FormatOptions
+		flags.BoolVar(&opts.AlignKeys, "align", false, "line up the values of each record")
+		flags.BoolVar(&opts.SortKeys, "sort", false, "order the keys of each record")
+		cmd = func(inputs []input, stdout io.Writer) error {
+			return format(inputs, stdout, opts)
+		}
+	case "validate":
+		cmd = validate
+	case "query":
+		cmd = func(inputs []input, stdout io.Writer) error {
+			return query(flags.Arg(0), inputs, stdout)
+		}
+	case "help", "-h", "-help", "--help":
+		fmt.Fprint(stdout, usage)
+		return 0
+	default:
+		fmt.Fprintf(stderr, "plain: unknown command %q\n\n%s", command, usage)
+		return 2
+	}
+
+	if err := flags.Parse(args[1:]); err != nil {
+		return 2
+	}
+	files := flags.Args()
+	if command == "query" {
+		if len(files) == 0 {
+			fmt.Fprintf(stderr, "plain: query needs an expression\n\n%s", usage)
+			return 2
+		}
+		files = files[1:]
+	}
+
+	inputs, err := readInputs(files, stdin)
+	if err != nil {
+		fmt.Fprintf(stderr, "plain: %s\n", err)
+		return 1
+	}
+
+	if err := cmd(inputs, stdout); err != nil {
+		// Joined errors are reported one per line
+		for _, line := range strings.Split(err.Error(), "\n") {
+			fmt.Fprintf(stderr, "plain: %s\n", line)
+		}
+		return 1
+	}
+
+	return 0
+}
+
+func readInputs(files []string, stdin io.Reader) ([]input, error) {
+	if len(files) == 0 {
+		data, err := io.ReadAll(stdin)
+		if err != nil {
+			return nil, err
+		}
+
+		return []input{{name: "<stdin>", data: data}}, nil
+	}
+
+	inputs := make([]input, 0, len(files))
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		inputs = append(inputs, input{name: file, data: data})
+	}
+
+	return inputs, nil
+}
+
+// decode reads every record of the inputs as a generic map
+func decode(inputs []input) ([]map[string]any, error) {
+	var records []map[string]any
+	for _, in := range inputs {
+		var inputRecords []map[string]any
+		if err := plain.Unmarshal(in.data, &inputRecords); err != nil {
+			return nil, fmt.Errorf("%s: %w", in.name, err)
+		}
+
+		records = append(records, inputRecords...)
+	}
+
+	return records, nil
+}
+
+// toJSON writes a single record as a JSON object and several as an array
+func toJSON(inputs []input, stdout io.Writer) error {
+	records, err := decode(inputs)
+	if err != nil {
+		return err
+	}
+
+	var out any = records
+	if len(records) == 1 {
+		out = records[0]
+	} else if records == nil {
+		out = []map[string]any{}
+	}
+
+	enc := json.NewEncoder(stdout)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(out)
+}
+
+// fromJSON accepts a JSON object or an array of objects per input
+func fromJSON(inputs []input, stdout io.Writer) error {
+	var records []any
+	for _, in := range inputs {
+		dec := json.NewDecoder(bytes.NewReader(in.data))
+		dec.UseNumber()
+
+		var value any
+		if err := dec.Decode(&value); err != nil {
+			return fmt.Errorf("%s: %w", in.name, err)
+		}
+
+		switch value := value.(type) {
+		case map[string]any:
+			records = append(records, value)
+		case []any:
+			for _, record := range value {
+				if _, ok := record.(map[string]any); !ok {
+					return fmt.Errorf("%s: array elements must be objects", in.name)
+				}
+			}
+			records = append(records, value...)
+		default:
+			return fmt.Errorf("%s: expected an object or an array of objects", in.name)
+		}
+	}
+
+	return writeRecords(records, stdout)
+}
+
+// format rewrites each input in canonical form, keeping comments
+func format(inputs []input, stdout io.Writer, opts plain.FormatOptions) error {
+	for i, in := range inputs {
+		formatted, err := plain.FormatWithOptions(in.data, opts)
+		if err != nil {
+			return fmt.Errorf("%s: %w", in.name, err)
+		}
+
+		if i > 0 && len(formatted) > 0 {
+			formatted = append([]byte("\n"), formatted...)
+		}
+		if _, err := stdout.Write(formatted); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func writeRecords(records []any, stdout io.Writer) error {
+	if len(records) == 0 {
+		return nil
+	}
+
+	data, err := plain.Marshal(records)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(stdout, "%s\n", data)
+	return err
+}
+
+// query prints the matches of each record as plain records, headed by a
+// comment holding the record index
+func query(expr string, inputs []input, stdout io.Writer) error {
+	record, printed := 0, false
+	for _, in := range inputs {
+		records, err := decode([]input{in})
+		if err != nil {
+			return err
+		}
+		matches, err := plain.QueryRecords(records, expr)
+		if err != nil {
+			return fmt.Errorf("%s: %w", in.name, err)
+		}
+
+		last := -1
+		for _, match := range matches {
+			if match.Record != last {
+				if printed {
+					fmt.Fprintln(stdout)
+				}
+				fmt.Fprintf(stdout, "# record %d\n", record+match.Record)
+				last = match.Record
+			}
+
+			data, err := plain.Marshal(map[string]any{match.Path: match.Value})
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(stdout, "%s\n", data)
+			printed = true
+		}
+
+		// Records are numbered across all inputs
+		record += len(records)
+	}
+
+	return nil
+}
+
+// validate reports the first line of each input that does not parse and
+// every value that cannot be decoded
+func validate(inputs []input, stdout io.Writer) error {
+	var errs []error
+	for _, in := range inputs {
+		var syntaxErr *plain.SyntaxError
+		if _, err := plain.Parse(in.data); errors.As(err, &syntaxErr) {
+			errs = append(errs, fmt.Errorf("%s:%d: %s", in.name, syntaxErr.Line, syntaxErr.Msg))
+		} else if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", in.name, err))
+		}
+
+		// Report every value that fails, not only the first
+		var records []map[string]any
+		dec := plain.NewDecoder(bytes.NewReader(in.data))
+		dec.CollectErrors()
+
+		err := dec.Decode(&records)
+		var decodeErrs plain.DecodeErrors
+		if errors.As(err, &decodeErrs) {
+			for _, e := range decodeErrs {
+				errs = append(errs, fmt.Errorf("%s:%d: %s: %w", in.name, e.Line, e.Key, e.Err))
+			}
+		} else if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", in.name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
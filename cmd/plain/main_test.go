@@ -0,0 +1,217 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func runCommand(t *testing.T, stdin string, args ...string) (string, string, int) {
+	t.Helper()
+
+	var stdout, stderr bytes.Buffer
+	code := run(args, strings.NewReader(stdin), &stdout, &stderr)
+
+	return stdout.String(), stderr.String(), code
+}
+
+func TestToJSON(t *testing.T) {
+	t.Run("Single", func(t *testing.T) {
+		stdout, stderr, code := runCommand(t, "name: John Doe\nsub.tags: [a, b]\n", "tojson")
+		if code != 0 {
+			t.Fatalf("tojson exited with %d: %s", code, stderr)
+		}
+
+		expected := "{\n  \"name\": \"John Doe\",\n  \"sub\": {\n    \"tags\": [\n      \"a\",\n      \"b\"\n    ]\n  }\n}\n"
+		if stdout != expected {
+			t.Fatalf("tojson was expecting %q\n got %q", expected, stdout)
+		}
+	})
+
+	t.Run("Multiple", func(t *testing.T) {
+		stdout, stderr, code := runCommand(t, "name: John Doe\n\nname: Jane Doe\n", "tojson")
+		if code != 0 {
+			t.Fatalf("tojson exited with %d: %s", code, stderr)
+		}
+
+		expected := "[\n  {\n    \"name\": \"John Doe\"\n  },\n  {\n    \"name\": \"Jane Doe\"\n  }\n]\n"
+		if stdout != expected {
+			t.Fatalf("tojson was expecting %q\n got %q", expected, stdout)
+		}
+	})
+}
+
+func TestFromJSON(t *testing.T) {
+	t.Run("Object", func(t *testing.T) {
+		stdout, stderr, code := runCommand(t, `{"name": "John Doe", "age": 42, "sub": {"active": true}}`, "fromjson")
+		if code != 0 {
+			t.Fatalf("fromjson exited with %d: %s", code, stderr)
+		}
+
+		expected := "age: 42\nname: John Doe\nsub.active: true\n"
+		if stdout != expected {
+			t.Fatalf("fromjson was expecting %q\n got %q", expected, stdout)
+		}
+	})
+
+	t.Run("Array", func(t *testing.T) {
+		stdout, stderr, code := runCommand(t, `[{"name": "John Doe"}, {"name": "Jane Doe"}]`, "fromjson")
+		if code != 0 {
+			t.Fatalf("fromjson exited with %d: %s", code, stderr)
+		}
+
+		expected := "name: John Doe\n\nname: Jane Doe\n"
+		if stdout != expected {
+			t.Fatalf("fromjson was expecting %q\n got %q", expected, stdout)
+		}
+	})
+
+	t.Run("Not an object", func(t *testing.T) {
+		_, _, code := runCommand(t, `"name"`, "fromjson")
+		if code != 1 {
+			t.Fatalf("fromjson was expecting exit code 1 got %d", code)
+		}
+	})
+}
+
+func TestFormat(t *testing.T) {
+	t.Run("Canonical", func(t *testing.T) {
+		stdout, stderr, code := runCommand(t, "# people\nname :John Doe\nage:42\n\n\n\nname:   Jane Doe\n", "fmt")
+		if code != 0 {
+			t.Fatalf("fmt exited with %d: %s", code, stderr)
+		}
+
+		expected := "# people\nname: John Doe\nage: 42\n\nname: Jane Doe\n"
+		if stdout != expected {
+			t.Fatalf("fmt was expecting %q\n got %q", expected, stdout)
+		}
+	})
+
+	t.Run("Flags", func(t *testing.T) {
+		stdout, stderr, code := runCommand(t, "name: John Doe\nage: 42\n", "fmt", "-align", "-sort")
+		if code != 0 {
+			t.Fatalf("fmt exited with %d: %s", code, stderr)
+		}
+
+		expected := "age:  42\nname: John Doe\n"
+		if stdout != expected {
+			t.Fatalf("fmt was expecting %q\n got %q", expected, stdout)
+		}
+	})
+
+	t.Run("Syntax error", func(t *testing.T) {
+		_, stderr, code := runCommand(t, "name: John Doe\n: Jane Doe\n", "fmt")
+		if code != 1 || !strings.Contains(stderr, "<stdin>: line 2") {
+			t.Fatalf("fmt was expecting a syntax error got %d %q", code, stderr)
+		}
+	})
+}
+
+func TestValidate(t *testing.T) {
+	t.Run("Valid", func(t *testing.T) {
+		_, stderr, code := runCommand(t, "name: John Doe\n\nname: Jane Doe\n", "validate")
+		if code != 0 {
+			t.Fatalf("validate exited with %d: %s", code, stderr)
+		}
+	})
+
+	t.Run("Values without keys", func(t *testing.T) {
+		_, stderr, code := runCommand(t, "1\n\n2\n", "validate")
+		if code != 0 {
+			t.Fatalf("validate exited with %d: %s", code, stderr)
+		}
+	})
+
+	t.Run("Continued lines and comments", func(t *testing.T) {
+		_, stderr, code := runCommand(t, "# people\nname: John \\\n  Doe\n", "validate")
+		if code != 0 {
+			t.Fatalf("validate exited with %d: %s", code, stderr)
+		}
+	})
+
+	t.Run("Escaped backslash", func(t *testing.T) {
+		// An even run of backslashes does not continue the line
+		_, stderr, code := runCommand(t, "path: a \\\\\n: Jane Doe\n", "validate")
+		if code != 1 || !strings.Contains(stderr, "<stdin>:2: expected key: value") {
+			t.Fatalf("validate was expecting a line error got %d %q", code, stderr)
+		}
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		_, stderr, code := runCommand(t, "name: John Doe\n: Jane Doe\nsub: a\nsub.name: b\n", "validate")
+		if code != 1 {
+			t.Fatalf("validate was expecting exit code 1 got %d", code)
+		}
+
+		if !strings.Contains(stderr, "<stdin>:2: expected key: value") {
+			t.Fatalf("validate was expecting a line error got %q", stderr)
+		}
+		if !strings.Contains(stderr, "<stdin>:4: sub.name: key is both a value and a parent") {
+			t.Fatalf("validate was expecting a decode error got %q", stderr)
+		}
+	})
+
+	t.Run("File", func(t *testing.T) {
+		file := filepath.Join(t.TempDir(), "data.txt")
+		if err := os.WriteFile(file, []byte(": bad line\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		_, stderr, code := runCommand(t, "", "validate", file)
+		if code != 1 || !strings.Contains(stderr, file+":1:") {
+			t.Fatalf("validate was expecting a file error got %d %q", code, stderr)
+		}
+	})
+}
+
+func TestUnknownCommand(t *testing.T) {
+	_, stderr, code := runCommand(t, "", "nope")
+	if code != 2 || !strings.Contains(stderr, "unknown command") {
+		t.Fatalf("was expecting a usage error got %d %q", code, stderr)
+	}
+}
+
+func TestQuery(t *testing.T) {
+	t.Run("Matches", func(t *testing.T) {
+		input := "name: John Doe\naddresses.home.city: New York\naddresses.work.city: Boston\n\nname: Jane Doe\n\nname: Jim Doe\naddresses.home.city: Chicago\n"
+		stdout, stderr, code := runCommand(t, input, "query", "addresses.*.city")
+		if code != 0 {
+			t.Fatalf("query exited with %d: %s", code, stderr)
+		}
+
+		expected := "# record 0\naddresses.home.city: New York\naddresses.work.city: Boston\n\n# record 2\naddresses.home.city: Chicago\n"
+		if stdout != expected {
+			t.Fatalf("query was expecting %q\n got %q", expected, stdout)
+		}
+	})
+
+	t.Run("Files", func(t *testing.T) {
+		dir := t.TempDir()
+		first, second := filepath.Join(dir, "first.txt"), filepath.Join(dir, "second.txt")
+		if err := os.WriteFile(first, []byte("age: 42\n\nage: 20\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(second, []byte("age: 36\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		stdout, stderr, code := runCommand(t, "", "query", "[age > 30].age", first, second)
+		if code != 0 {
+			t.Fatalf("query exited with %d: %s", code, stderr)
+		}
+
+		expected := "# record 0\nage: 42\n\n# record 2\nage: 36\n"
+		if stdout != expected {
+			t.Fatalf("query was expecting %q\n got %q", expected, stdout)
+		}
+	})
+
+	t.Run("Missing expression", func(t *testing.T) {
+		_, _, code := runCommand(t, "", "query")
+		if code != 2 {
+			t.Fatalf("query was expecting exit code 2 got %d", code)
+		}
+	})
+}
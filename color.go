@@ -0,0 +1,94 @@
+package plain
+
+import (
+	"io"
+	"os"
+	"reflect"
+)
+
+// A Theme sets the colors of Encoder output as ANSI SGR parameters, such as
+// "36" for cyan or "1;33" for bold yellow. An empty parameter leaves that
+// part uncolored.
+type Theme struct {
+	Key    string // keys
+	Value  string // strings and other values
+	Number string // integers, floats and durations
+	Bool   string // true and false
+	Nil    string // nil values, written as <nil>
+}
+
+// DefaultTheme colors keys blue, numbers yellow, booleans magenta and nil
+// values dim, leaving strings uncolored.
+var DefaultTheme = Theme{
+	Key:    "34",
+	Number: "33",
+	Bool:   "35",
+	Nil:    "2",
+}
+
+// valueKind is the part of a theme a value is colored with
+type valueKind int
+
+const (
+	kindValue valueKind = iota
+	kindNumber
+	kindBool
+	kindNil
+)
+
+// kindOf returns the value kind of a value added as a row
+func kindOf(value any) valueKind {
+	if value == nil {
+		return kindNil
+	}
+
+	switch reflect.ValueOf(value).Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return kindNumber
+	case reflect.Bool:
+		return kindBool
+	}
+
+	return kindValue
+}
+
+// colorize wraps text in the SGR sequence of a theme parameter
+func colorize(parameter, text string) string {
+	if parameter == "" || text == "" {
+		return text
+	}
+
+	return "\x1b[" + parameter + "m" + text + "\x1b[0m"
+}
+
+// color returns the theme parameter for a value kind
+func (t *Theme) color(kind valueKind) string {
+	switch kind {
+	case kindNumber:
+		return t.Number
+	case kindBool:
+		return t.Bool
+	case kindNil:
+		return t.Nil
+	}
+
+	return t.Value
+}
+
+// colorEnabled checks if w is a terminal and NO_COLOR is not set to a
+// non-empty value
+func colorEnabled(w io.Writer) bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
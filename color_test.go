@@ -0,0 +1,70 @@
+package plain
+
+import (
+	"bytes"
+	"os"
+	"testing"
+	"time"
+)
+
+type TestColor struct {
+	Name    string        `plain:"name"`
+	Age     int           `plain:"age"`
+	Active  bool          `plain:"active"`
+	Timeout time.Duration `plain:"timeout"`
+	Extra   any           `plain:"extra"`
+}
+
+func TestColorOutput(t *testing.T) {
+	value := TestColor{Name: "John", Age: 42, Active: true, Timeout: time.Second}
+
+	t.Run("Theme", func(t *testing.T) {
+		resp, err := newEncodeState(encodeOptions{theme: &DefaultTheme, align: true}).marshal(value)
+		if err != nil {
+			t.Fatalf("Was not expecting an error got %s", err)
+		}
+
+		expected := "\x1b[34mname\x1b[0m:    John\n" +
+			"\x1b[34mage\x1b[0m:     \x1b[33m42\x1b[0m\n" +
+			"\x1b[34mactive\x1b[0m:  \x1b[35mtrue\x1b[0m\n" +
+			"\x1b[34mtimeout\x1b[0m: \x1b[33m1s\x1b[0m\n" +
+			"\x1b[34mextra\x1b[0m:   \x1b[2m<nil>\x1b[0m"
+		if string(resp) != expected {
+			t.Fatalf("Color was expecting %q\n got %q", expected, resp)
+		}
+	})
+
+	t.Run("Not a terminal", func(t *testing.T) {
+		var buf bytes.Buffer
+		enc := NewEncoder(&buf)
+		enc.SetColor(DefaultTheme)
+		if err := enc.Encode(value); err != nil {
+			t.Fatalf("Was not expecting an error got %s", err)
+		}
+
+		expected, _ := Marshal(value)
+		if buf.String() != string(expected)+"\n" {
+			t.Fatalf("Encoder was expecting uncolored %q\n got %q", expected, buf.String())
+		}
+	})
+
+	t.Run("Pipe", func(t *testing.T) {
+		r, w, err := os.Pipe()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer r.Close()
+		defer w.Close()
+
+		if colorEnabled(w) {
+			t.Fatal("Was not expecting color for a pipe")
+		}
+	})
+
+	t.Run("NO_COLOR", func(t *testing.T) {
+		t.Setenv("NO_COLOR", "1")
+		if colorEnabled(os.Stdout) {
+			t.Fatal("Was not expecting color with NO_COLOR set")
+		}
+	})
+}
@@ -0,0 +1,96 @@
+package plain
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// DefaultMaxDepth is the deepest a value may nest when no maximum depth is
+// set. Every struct, map and slice a value is in counts as one level, so the
+// key a.b.c is at depth 3.
+const DefaultMaxDepth = 64
+
+// ErrMaxDepth is returned when a value nests deeper than the maximum depth.
+var ErrMaxDepth = errors.New("maximum depth exceeded")
+
+// A CycleError is returned by Marshal when a pointer or map refers back to a
+// value that holds it, such as a tree node with a parent pointer.
+type CycleError struct {
+	Key  string       // key of the value closing the cycle
+	Type reflect.Type // type of the pointer or map
+}
+
+func (e *CycleError) Error() string {
+	return fmt.Sprintf("cycle through %s at %s", e.Type, e.Key)
+}
+
+// visit identifies a pointer or map being encoded
+type visit struct {
+	typ reflect.Type
+	ptr uintptr
+}
+
+// enter marks a pointer or map as being encoded under key, it fails when
+// the value is already being encoded further up
+func (e *encodeState) enter(key string, val reflect.Value) error {
+	v := visit{typ: val.Type(), ptr: val.Pointer()}
+	if e.visiting[v] {
+		return &CycleError{Key: key, Type: v.typ}
+	}
+	if e.visiting == nil {
+		e.visiting = map[visit]bool{}
+	}
+	e.visiting[v] = true
+
+	return nil
+}
+
+// leave unmarks a pointer or map once it has been encoded
+func (e *encodeState) leave(val reflect.Value) {
+	delete(e.visiting, visit{typ: val.Type(), ptr: val.Pointer()})
+}
+
+// elementKey returns the key of a value within element i of the slice at
+// parent, for errors
+func elementKey(parent string, i int, key, separator string) string {
+	elementKey := fmt.Sprintf("%s[%d]", parent, i)
+	if key == "" {
+		return elementKey
+	}
+
+	return elementKey + separator + key
+}
+
+// depthError is ErrMaxDepth for the value at a key
+type depthError struct {
+	key string
+}
+
+func (e *depthError) Error() string {
+	if e.key == "" {
+		return ErrMaxDepth.Error()
+	}
+
+	return e.key + ": " + ErrMaxDepth.Error()
+}
+
+func (e *depthError) Unwrap() error {
+	return ErrMaxDepth
+}
+
+// descend goes one level deeper into the value at key
+func (e *encodeState) descend(key string) error {
+	if e.depth >= e.maxDepth {
+		return &depthError{key: key}
+	}
+	e.depth++
+
+	return nil
+}
+
+// keyDepth returns the depth of the value at key
+func (d *decodeState) keyDepth(key string) int {
+	return strings.Count(key, d.dialect.PathSeparator) + 1
+}
@@ -0,0 +1,199 @@
+package plain
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type TestNode struct {
+	Name     string      `plain:"name"`
+	Parent   *TestNode   `plain:"parent"`
+	Children []*TestNode `plain:"children"`
+}
+
+type TestPointers struct {
+	Name  *string   `plain:"name"`
+	Age   *int      `plain:"age"`
+	Owner *TestNode `plain:"owner"`
+}
+
+func TestCycles(t *testing.T) {
+	t.Run("Parent pointer", func(t *testing.T) {
+		root := &TestNode{Name: "root"}
+		child := &TestNode{Name: "child", Parent: root}
+		root.Children = []*TestNode{child}
+
+		for value, key := range map[*TestNode]string{root: "children[0].parent", child: "parent.children[0]"} {
+			_, err := Marshal(value)
+			var cycle *CycleError
+			if !errors.As(err, &cycle) {
+				t.Fatalf("Marshal was expecting a cycle error got %v", err)
+			}
+			if cycle.Key != key {
+				t.Fatalf("CycleError was expecting key %q\n got %q", key, cycle.Key)
+			}
+		}
+	})
+
+	t.Run("Map", func(t *testing.T) {
+		m := map[string]any{"name": "loop"}
+		m["self"] = m
+
+		_, err := Marshal(m)
+		var cycle *CycleError
+		if !errors.As(err, &cycle) || cycle.Key != "self" {
+			t.Fatalf("Marshal was expecting a cycle error at self got %v", err)
+		}
+	})
+
+	t.Run("Shared pointer", func(t *testing.T) {
+		shared := &TestNode{Name: "shared"}
+		value := struct {
+			First  *TestNode `plain:"first"`
+			Second *TestNode `plain:"second"`
+		}{First: shared, Second: shared}
+
+		resp, err := Marshal(value)
+		if err != nil {
+			t.Fatalf("Was not expecting an error got %s", err)
+		}
+
+		expected := "first.name: shared\nfirst.parent: <nil>\nfirst.children: []\nsecond.name: shared\nsecond.parent: <nil>\nsecond.children: []"
+		if string(resp) != expected {
+			t.Fatalf("Marshal was expecting %q\n got %q", expected, string(resp))
+		}
+	})
+}
+
+func TestPointerFields(t *testing.T) {
+	t.Run("Round trip", func(t *testing.T) {
+		name, age := "John Doe", 42
+		value := TestPointers{Name: &name, Age: &age, Owner: &TestNode{Name: "root"}}
+
+		resp, err := Marshal(value)
+		if err != nil {
+			t.Fatalf("Was not expecting an error got %s", err)
+		}
+
+		expected := "name: John Doe\nage: 42\nowner.name: root\nowner.parent: <nil>\nowner.children: []"
+		if string(resp) != expected {
+			t.Fatalf("Marshal was expecting %q\n got %q", expected, string(resp))
+		}
+
+		var result TestPointers
+		if err := Unmarshal(resp, &result); err != nil {
+			t.Fatalf("Was not expecting an error got %s", err)
+		}
+		if result.Name == nil || *result.Name != name || result.Age == nil || *result.Age != age {
+			t.Fatalf("Unmarshal got %+v", result)
+		}
+		if result.Owner == nil || result.Owner.Name != "root" || result.Owner.Parent != nil {
+			t.Fatalf("Unmarshal got owner %+v", result.Owner)
+		}
+	})
+
+	t.Run("Slice elements", func(t *testing.T) {
+		tag := "go"
+		value := struct {
+			Tags []*string `plain:"tags"`
+		}{Tags: []*string{&tag, nil}}
+
+		resp, err := Marshal(value)
+		if err != nil {
+			t.Fatalf("Was not expecting an error got %s", err)
+		}
+
+		expected := "tags: [go, <nil>]"
+		if string(resp) != expected {
+			t.Fatalf("Marshal was expecting %q\n got %q", expected, string(resp))
+		}
+	})
+}
+
+func TestMaxDepth(t *testing.T) {
+	t.Run("Encode", func(t *testing.T) {
+		value := map[string]any{"a": map[string]any{"b": map[string]any{"c": "deep"}}}
+
+		var buf bytes.Buffer
+		enc := NewEncoder(&buf)
+		enc.SetMaxDepth(2)
+		err := enc.Encode(value)
+		if !errors.Is(err, ErrMaxDepth) || !strings.HasPrefix(err.Error(), "a.b:") {
+			t.Fatalf("Encode was expecting a max depth error at a.b got %v", err)
+		}
+
+		enc.SetMaxDepth(3)
+		if err := enc.Encode(value); err != nil {
+			t.Fatalf("Was not expecting an error got %s", err)
+		}
+	})
+
+	t.Run("Encode slice elements", func(t *testing.T) {
+		value := map[string]any{"list": []any{map[string]any{"a": map[string]any{"b": "deep"}}}}
+
+		var buf bytes.Buffer
+		enc := NewEncoder(&buf)
+		enc.SetMaxDepth(3)
+		err := enc.Encode(value)
+		if !errors.Is(err, ErrMaxDepth) || err.Error() != "list[0].a: maximum depth exceeded" {
+			t.Fatalf("Encode was expecting a max depth error at list[0].a got %v", err)
+		}
+	})
+
+	t.Run("Decode", func(t *testing.T) {
+		dec := NewDecoder(strings.NewReader("name: ok\na.b.c: deep\n"))
+		dec.SetMaxDepth(2)
+
+		var result map[string]any
+		err := dec.Decode(&result)
+		var decodeErr *DecodeError
+		if !errors.As(err, &decodeErr) || decodeErr.Line != 2 || !errors.Is(err, ErrMaxDepth) {
+			t.Fatalf("Decode was expecting a max depth error on line 2 got %v", err)
+		}
+	})
+
+	t.Run("Nested lists", func(t *testing.T) {
+		type node struct {
+			Name     string `plain:"name"`
+			Children []node `plain:"children"`
+		}
+		data := strings.Repeat("children: [", 200) + "name: leaf" + strings.Repeat("]", 200)
+
+		var result node
+		err := Unmarshal([]byte(data), &result)
+		if !errors.Is(err, ErrMaxDepth) {
+			t.Fatalf("Unmarshal was expecting a max depth error got %v", err)
+		}
+		if err.Error() != "line 1: children: maximum depth exceeded" {
+			t.Fatalf("Unmarshal was expecting the error reported once got %q", err)
+		}
+
+		// Each list and each key is a level
+		shallow := strings.Repeat("children: [", 3) + "name: leaf" + strings.Repeat("]", 3)
+		dec := NewDecoder(strings.NewReader(shallow))
+		dec.SetMaxDepth(7)
+		if err := dec.Decode(&result); err != nil {
+			t.Fatalf("Was not expecting an error got %s", err)
+		}
+		if result.Children[0].Children[0].Children[0].Name != "leaf" {
+			t.Fatalf("Decode got %+v", result)
+		}
+
+		dec = NewDecoder(strings.NewReader(shallow))
+		dec.SetMaxDepth(6)
+		if err := dec.Decode(&result); !errors.Is(err, ErrMaxDepth) {
+			t.Fatalf("Decode was expecting a max depth error got %v", err)
+		}
+	})
+
+	t.Run("Default", func(t *testing.T) {
+		key := strings.Repeat("a.", DefaultMaxDepth) + "a"
+
+		var result map[string]any
+		if err := Unmarshal([]byte(key+": deep\n"), &result); !errors.Is(err, ErrMaxDepth) {
+			t.Fatalf("Unmarshal was expecting a max depth error got %v", err)
+		}
+	})
+}
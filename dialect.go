@@ -0,0 +1,135 @@
+package plain
+
+// Syntax selects the line rules of a dialect.
+type Syntax int
+
+const (
+	// SyntaxPlain reads one key and value per line, split on the first
+	// Separator. Lines starting with # are comments, and a line ending in a
+	// space and a backslash continues on the next line.
+	SyntaxPlain Syntax = iota
+
+	// SyntaxProperties follows Java .properties files: keys end at the first
+	// =, : or whitespace, lines starting with # or ! are comments, a trailing
+	// backslash continues a line and backslash escapes such as \uXXXX are
+	// resolved. Marshal escapes keys and values the same way.
+	SyntaxProperties
+
+	// SyntaxDotenv follows .env files: an optional export prefix, single
+	// quoted literal values, double quoted values with escapes that may span
+	// lines and # comments, also after unquoted values. Marshal quotes values
+	// that would not read back as is.
+	SyntaxDotenv
+)
+
+// A Dialect sets the tokens used to write and read plain text. Start from
+// one of the presets and change the fields that differ.
+type Dialect struct {
+	// Separator is written between a key and its value. Decoding splits each
+	// line on the first occurrence of Separator with spaces trimmed.
+	Separator string
+
+	// RecordSeparator is written between records and splits records when
+	// decoding.
+	RecordSeparator string
+
+	// ListStart and ListEnd wrap list values. When both are empty, every
+	// value decoded into a slice is split on ListSeparator.
+	ListStart string
+	ListEnd   string
+
+	// ListSeparator is written between list elements. Decoding splits on it
+	// with spaces trimmed.
+	ListSeparator string
+
+	// PathSeparator joins the keys of nested structs and maps.
+	PathSeparator string
+
+	// UpperCaseKeys writes keys in upper case. Keys are always matched
+	// without regard to case when decoding.
+	UpperCaseKeys bool
+
+	// Syntax selects the rules for comments, escapes and continued lines.
+	Syntax Syntax
+}
+
+var (
+	// DialectDefault is the format written by Marshal: key: value lines,
+	// records separated by a blank line and lists such as [a, b].
+	DialectDefault = Dialect{
+		Separator:       ": ",
+		RecordSeparator: "\n\n",
+		ListStart:       "[",
+		ListEnd:         "]",
+		ListSeparator:   ", ",
+		PathSeparator:   ".",
+	}
+
+	// DialectEnv reads and writes .env files of KEY=value lines, with nested
+	// keys joined by underscores and comma separated lists.
+	DialectEnv = Dialect{
+		Separator:       "=",
+		RecordSeparator: "\n\n",
+		ListSeparator:   ",",
+		PathSeparator:   "_",
+		UpperCaseKeys:   true,
+		Syntax:          SyntaxDotenv,
+	}
+
+	// DialectProperties reads and writes Java .properties files, with dotted
+	// keys and comma separated lists.
+	DialectProperties = Dialect{
+		Separator:       "=",
+		RecordSeparator: "\n\n",
+		ListSeparator:   ",",
+		PathSeparator:   ".",
+		Syntax:          SyntaxProperties,
+	}
+)
+
+// MarshalDialect is like Marshal but writes in the given dialect.
+func MarshalDialect(v any, dialect Dialect) ([]byte, error) {
+	return newEncodeState(encodeOptions{dialect: dialect.withDefaults()}).marshal(v)
+}
+
+// UnmarshalDialect is like Unmarshal but reads the given dialect.
+func UnmarshalDialect(data []byte, v any, dialect Dialect) error {
+	return newDecodeState(decodeOptions{dialect: dialect.withDefaults()}).unmarshal(data, v)
+}
+
+// withDefaults fills the separators a dialect cannot work without
+func (d Dialect) withDefaults() Dialect {
+	if d.Separator == "" {
+		d.Separator = DialectDefault.Separator
+	}
+	if d.RecordSeparator == "" {
+		d.RecordSeparator = DialectDefault.RecordSeparator
+	}
+	if d.ListSeparator == "" {
+		d.ListSeparator = DialectDefault.ListSeparator
+	}
+	if d.PathSeparator == "" {
+		d.PathSeparator = DialectDefault.PathSeparator
+	}
+
+	return d
+}
+
+// isList checks if a trimmed value is wrapped in the list tokens and
+// returns its content
+func (d Dialect) isList(value string) (string, bool) {
+	if d.ListStart == "" && d.ListEnd == "" {
+		return value, true
+	}
+	if !hasAffixes(value, d.ListStart, d.ListEnd) {
+		return "", false
+	}
+
+	return value[len(d.ListStart) : len(value)-len(d.ListEnd)], true
+}
+
+// hasAffixes checks if value starts with prefix and ends with suffix without
+// the two overlapping
+func hasAffixes(value, prefix, suffix string) bool {
+	return len(value) >= len(prefix)+len(suffix) && value[:len(prefix)] == prefix && value[len(value)-len(suffix):] == suffix
+}
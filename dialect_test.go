@@ -0,0 +1,171 @@
+package plain
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type TestDialectDB struct {
+	Host     string `plain:"host"`
+	PoolSize int    `plain:"pool_size"`
+}
+
+type TestDialect struct {
+	Name string        `plain:"name"`
+	Tags []string      `plain:"tags"`
+	DB   TestDialectDB `plain:"db"`
+}
+
+var legacyDialect = Dialect{
+	Separator:       "=",
+	RecordSeparator: "\n---\n",
+	ListSeparator:   "|",
+	PathSeparator:   ".",
+}
+
+func TestMarshalDialect(t *testing.T) {
+	value := TestDialect{Name: "api", Tags: []string{"a", "b"}, DB: TestDialectDB{Host: "localhost", PoolSize: 5}}
+
+	t.Run("Env", func(t *testing.T) {
+		resp, err := MarshalDialect(value, DialectEnv)
+		if err != nil {
+			t.Fatalf("Was not expecting an error got %s", err)
+		}
+
+		expected := "NAME=api\nTAGS=a,b\nDB_HOST=localhost\nDB_POOL_SIZE=5"
+		if string(resp) != expected {
+			t.Fatalf("MarshalDialect was expecting %q\n got %q", expected, resp)
+		}
+	})
+
+	t.Run("Properties", func(t *testing.T) {
+		resp, err := MarshalDialect(value, DialectProperties)
+		if err != nil {
+			t.Fatalf("Was not expecting an error got %s", err)
+		}
+
+		expected := "name=api\ntags=a,b\ndb.host=localhost\ndb.pool_size=5"
+		if string(resp) != expected {
+			t.Fatalf("MarshalDialect was expecting %q\n got %q", expected, resp)
+		}
+	})
+
+	t.Run("Records", func(t *testing.T) {
+		resp, err := MarshalDialect([]TestDialect{{Name: "a", Tags: []string{"x", "y"}}, {Name: "b"}}, legacyDialect)
+		if err != nil {
+			t.Fatalf("Was not expecting an error got %s", err)
+		}
+
+		expected := "name=a\ntags=x|y\ndb.host=\ndb.pool_size=0\n---\nname=b\ntags=\ndb.host=\ndb.pool_size=0"
+		if string(resp) != expected {
+			t.Fatalf("MarshalDialect was expecting %q\n got %q", expected, resp)
+		}
+	})
+
+	t.Run("Default", func(t *testing.T) {
+		resp, err := MarshalDialect(value, DialectDefault)
+		if err != nil {
+			t.Fatalf("Was not expecting an error got %s", err)
+		}
+
+		expected, _ := Marshal(value)
+		if string(resp) != string(expected) {
+			t.Fatalf("MarshalDialect was expecting %q\n got %q", expected, resp)
+		}
+	})
+}
+
+func TestUnmarshalDialect(t *testing.T) {
+	expected := TestDialect{Name: "api", Tags: []string{"a", "b"}, DB: TestDialectDB{Host: "localhost", PoolSize: 5}}
+
+	t.Run("Env", func(t *testing.T) {
+		var result TestDialect
+		data := "NAME=api\nTAGS=a, b\nDB_HOST=localhost\nDB_POOL_SIZE=5\n"
+		if err := UnmarshalDialect([]byte(data), &result, DialectEnv); err != nil {
+			t.Fatalf("Was not expecting an error got %s", err)
+		}
+		if !reflect.DeepEqual(result, expected) {
+			t.Fatalf("UnmarshalDialect was expecting %+v\n got %+v", expected, result)
+		}
+	})
+
+	t.Run("Properties", func(t *testing.T) {
+		var result TestDialect
+		data := "name = api\ntags = a,b\ndb.host = localhost\ndb.pool_size = 5\n"
+		if err := UnmarshalDialect([]byte(data), &result, DialectProperties); err != nil {
+			t.Fatalf("Was not expecting an error got %s", err)
+		}
+		if !reflect.DeepEqual(result, expected) {
+			t.Fatalf("UnmarshalDialect was expecting %+v\n got %+v", expected, result)
+		}
+	})
+
+	t.Run("Records", func(t *testing.T) {
+		var result []TestDialect
+		data := "name=a\ntags=x | y\n---\nname=b\n"
+		if err := UnmarshalDialect([]byte(data), &result, legacyDialect); err != nil {
+			t.Fatalf("Was not expecting an error got %s", err)
+		}
+
+		want := []TestDialect{{Name: "a", Tags: []string{"x", "y"}}, {Name: "b"}}
+		if !reflect.DeepEqual(result, want) {
+			t.Fatalf("UnmarshalDialect was expecting %+v\n got %+v", want, result)
+		}
+	})
+
+	t.Run("Error line", func(t *testing.T) {
+		var result []TestDialect
+		data := "name=a\n---\nname=b\ndb.pool_size=x\n"
+		err := UnmarshalDialect([]byte(data), &result, legacyDialect)
+
+		decodeErr, ok := err.(*DecodeError)
+		if !ok {
+			t.Fatalf("Was expecting a DecodeError got %v", err)
+		}
+		if decodeErr.Line != 4 {
+			t.Fatalf("Was expecting line 4 got %d", decodeErr.Line)
+		}
+	})
+
+	t.Run("Map", func(t *testing.T) {
+		result := map[string]any{}
+		if err := UnmarshalDialect([]byte("DB_HOST=localhost\nTAGS=a,b"), &result, DialectEnv); err != nil {
+			t.Fatalf("Was not expecting an error got %s", err)
+		}
+
+		want := map[string]any{"DB": map[string]any{"HOST": "localhost"}, "TAGS": "a,b"}
+		if !reflect.DeepEqual(result, want) {
+			t.Fatalf("UnmarshalDialect was expecting %v\n got %v", want, result)
+		}
+	})
+}
+
+func TestStreamDialect(t *testing.T) {
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf)
+	enc.SetDialect(legacyDialect)
+	for _, name := range []string{"a", "b"} {
+		if err := enc.Encode(TestDialect{Name: name}); err != nil {
+			t.Fatalf("Was not expecting an error got %s", err)
+		}
+	}
+
+	expected := "name=a\ntags=\ndb.host=\ndb.pool_size=0\n---\nname=b\ntags=\ndb.host=\ndb.pool_size=0\n"
+	if buf.String() != expected {
+		t.Fatalf("Encoder was expecting %q\n got %q", expected, buf.String())
+	}
+
+	var result []TestDialect
+	dec := NewDecoder(strings.NewReader(buf.String()))
+	dec.SetDialect(legacyDialect)
+	if err := dec.Decode(&result); err != nil {
+		t.Fatalf("Was not expecting an error got %s", err)
+	}
+
+	want := []TestDialect{{Name: "a"}, {Name: "b"}}
+	if !reflect.DeepEqual(result, want) {
+		t.Fatalf("Decoder was expecting %+v\n got %+v", want, result)
+	}
+}
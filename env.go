@@ -0,0 +1,168 @@
+package plain
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// FromEnv fills the struct pointed to by v from the process environment.
+// Variables named prefix_KEY are matched like keys of DialectEnv, so with
+// the prefix APP the variable APP_SERVER_PORT sets the field tagged port
+// inside the struct tagged server. An empty prefix matches every variable.
+func FromEnv(prefix string, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return errors.New("v must be a non-nil pointer")
+	}
+
+	rv = rv.Elem()
+	if rv.Kind() != reflect.Struct {
+		return errors.New("v must point to a struct")
+	}
+
+	// Sort variables so repeated runs fill and fail in the same order
+	environ := os.Environ()
+	sort.Strings(environ)
+
+	d := newDecodeState(decodeOptions{dialect: DialectEnv})
+	seen := map[string]bool{}
+	for _, variable := range environ {
+		name, value, _ := strings.Cut(variable, "=")
+		key := name
+		if prefix != "" {
+			if len(name) <= len(prefix)+1 || !strings.EqualFold(name[:len(prefix)], prefix) || name[len(prefix)] != '_' {
+				continue
+			}
+			key = name[len(prefix)+1:]
+		}
+
+		if err := d.setFieldValue(rv, key, value, true); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		seen[strings.ToLower(key)] = true
+	}
+
+	if err := d.finishStruct(rv, seen); err != nil {
+		return err
+	}
+
+	return d.finish()
+}
+
+// dotenvLines joins the lines of a quoted value that spans several lines.
+// Each logical line keeps the number of its first physical line.
+func dotenvLines(lines []sourceLine) []sourceLine {
+	var joined []sourceLine
+	for i := 0; i < len(lines); i++ {
+		current := lines[i]
+		text := strings.TrimRight(current.text, "\r")
+		for openQuote(text) && i+1 < len(lines) {
+			i++
+			text += "\n" + strings.TrimRight(lines[i].text, "\r")
+		}
+
+		joined = append(joined, sourceLine{text: text, line: current.line})
+	}
+
+	return joined
+}
+
+// openQuote checks if the value of a dotenv line starts a quote that the
+// line does not close
+func openQuote(line string) bool {
+	if isComment(strings.TrimSpace(line)) {
+		return false
+	}
+
+	_, value, found := strings.Cut(line, "=")
+	value = strings.TrimLeft(value, " \t")
+	if !found || value == "" || (value[0] != '"' && value[0] != '\'') {
+		return false
+	}
+
+	_, err := closingQuote(value)
+	return err != nil
+}
+
+// splitDotenv splits a dotenv line into its key and value, removing an
+// export prefix, quotes and trailing comments
+func splitDotenv(line string) (string, string, bool, error) {
+	line = strings.TrimSpace(line)
+	if rest, ok := strings.CutPrefix(line, "export"); ok && rest != "" && (rest[0] == ' ' || rest[0] == '\t') {
+		line = strings.TrimLeft(rest, " \t")
+	}
+
+	key, value, found := strings.Cut(line, "=")
+	if !found {
+		return "", "", false, nil
+	}
+	key = strings.TrimSpace(key)
+	value = strings.TrimLeft(value, " \t")
+
+	if value != "" && (value[0] == '"' || value[0] == '\'') {
+		unquoted, err := closingQuote(value)
+		return key, unquoted, true, err
+	}
+
+	// An unquoted value ends at a # that follows whitespace
+	for i := 1; i < len(value); i++ {
+		if value[i] == '#' && (value[i-1] == ' ' || value[i-1] == '\t') {
+			value = value[:i]
+			break
+		}
+	}
+
+	return key, strings.TrimSpace(value), true, nil
+}
+
+// closingQuote returns the content of the quoted value at the start of
+// value. Single quotes are literal, double quotes resolve \n, \r, \t and
+// escaped quotes, backslashes and dollar signs.
+func closingQuote(value string) (string, error) {
+	quote := value[0]
+	var sb strings.Builder
+	for i := 1; i < len(value); i++ {
+		switch {
+		case value[i] == quote:
+			return sb.String(), nil
+		case quote == '"' && value[i] == '\\' && i+1 < len(value):
+			i++
+			switch value[i] {
+			case 'n':
+				sb.WriteByte('\n')
+			case 'r':
+				sb.WriteByte('\r')
+			case 't':
+				sb.WriteByte('\t')
+			case '"', '\\', '$':
+				sb.WriteByte(value[i])
+			default:
+				sb.WriteByte('\\')
+				sb.WriteByte(value[i])
+			}
+		default:
+			sb.WriteByte(value[i])
+		}
+	}
+
+	return "", errors.New("unterminated quoted value")
+}
+
+// quoteDotenv quotes a value when it would not read back as is. Values
+// without single quotes or newlines are single quoted, others are double
+// quoted with escapes.
+func quoteDotenv(value string) string {
+	if !strings.ContainsAny(value, " \t\r\n#\"'\\$") {
+		return value
+	}
+	if !strings.ContainsAny(value, "'\r\n") {
+		return "'" + value + "'"
+	}
+
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`, `$`, `\$`, "\n", `\n`, "\r", `\r`)
+	return `"` + replacer.Replace(value) + `"`
+}
@@ -0,0 +1,140 @@
+package plain
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+type TestEnvServer struct {
+	Host    string        `plain:"host"`
+	Port    int           `plain:"port"`
+	Timeout time.Duration `plain:"timeout,default=5s"`
+}
+
+type TestEnv struct {
+	Name   string        `plain:"name,required"`
+	Tags   []string      `plain:"tags"`
+	Server TestEnvServer `plain:"server"`
+}
+
+func TestUnmarshalDotenv(t *testing.T) {
+	t.Run("Syntax", func(t *testing.T) {
+		var result TestEnv
+		data := strings.Join([]string{
+			"# settings",
+			"export NAME=\"my app\" # quoted",
+			"TAGS=a,b # trailing comment",
+			"SERVER_HOST='local#host'",
+			"SERVER_PORT = 8080",
+			"",
+		}, "\n")
+		if err := UnmarshalDialect([]byte(data), &result, DialectEnv); err != nil {
+			t.Fatalf("Was not expecting an error got %s", err)
+		}
+
+		expected := TestEnv{Name: "my app", Tags: []string{"a", "b"}, Server: TestEnvServer{Host: "local#host", Port: 8080, Timeout: 5 * time.Second}}
+		if !reflect.DeepEqual(result, expected) {
+			t.Fatalf("UnmarshalDialect was expecting %+v\n got %+v", expected, result)
+		}
+	})
+
+	t.Run("Multiline", func(t *testing.T) {
+		var result TestEnv
+		data := "NAME=\"line one\nline \\\"two\\\"\"\nSERVER_PORT=x\n"
+		err := UnmarshalDialect([]byte(data), &result, DialectEnv)
+		if result.Name != "line one\nline \"two\"" {
+			t.Fatalf("UnmarshalDialect was expecting a multiline value got %q", result.Name)
+		}
+
+		var decodeErr *DecodeError
+		if !errors.As(err, &decodeErr) || decodeErr.Line != 3 {
+			t.Fatalf("Was expecting a DecodeError on line 3 got %v", err)
+		}
+	})
+
+	t.Run("Unterminated", func(t *testing.T) {
+		var result TestEnv
+		err := UnmarshalDialect([]byte("NAME='open\n"), &result, DialectEnv)
+
+		var decodeErr *DecodeError
+		if !errors.As(err, &decodeErr) || decodeErr.Key != "NAME" {
+			t.Fatalf("Was expecting a DecodeError for NAME got %v", err)
+		}
+	})
+}
+
+func TestMarshalDotenv(t *testing.T) {
+	value := TestEnv{Name: "it's a \"test\"\n", Tags: []string{"a"}, Server: TestEnvServer{Host: "local host", Port: 80, Timeout: time.Second}}
+	resp, err := MarshalDialect(value, DialectEnv)
+	if err != nil {
+		t.Fatalf("Was not expecting an error got %s", err)
+	}
+
+	expected := "NAME=\"it's a \\\"test\\\"\\n\"\nTAGS=a\nSERVER_HOST='local host'\nSERVER_PORT=80\nSERVER_TIMEOUT=1s"
+	if string(resp) != expected {
+		t.Fatalf("MarshalDialect was expecting %q\n got %q", expected, resp)
+	}
+
+	var result TestEnv
+	if err := UnmarshalDialect(resp, &result, DialectEnv); err != nil {
+		t.Fatalf("Was not expecting an error got %s", err)
+	}
+	if !reflect.DeepEqual(result, value) {
+		t.Fatalf("Round trip was expecting %+v\n got %+v", value, result)
+	}
+}
+
+func TestFromEnv(t *testing.T) {
+	t.Run("Prefix", func(t *testing.T) {
+		t.Setenv("TESTAPP_NAME", "api")
+		t.Setenv("TESTAPP_TAGS", "a, b")
+		t.Setenv("TESTAPP_SERVER_PORT", "9000")
+		t.Setenv("OTHER_NAME", "other")
+
+		var result TestEnv
+		if err := FromEnv("TESTAPP", &result); err != nil {
+			t.Fatalf("Was not expecting an error got %s", err)
+		}
+
+		expected := TestEnv{Name: "api", Tags: []string{"a", "b"}, Server: TestEnvServer{Port: 9000, Timeout: 5 * time.Second}}
+		if !reflect.DeepEqual(result, expected) {
+			t.Fatalf("FromEnv was expecting %+v\n got %+v", expected, result)
+		}
+	})
+
+	t.Run("Invalid value", func(t *testing.T) {
+		t.Setenv("TESTAPP_NAME", "api")
+		t.Setenv("TESTAPP_SERVER_PORT", "nope")
+
+		var result TestEnv
+		err := FromEnv("TESTAPP", &result)
+		if err == nil || !strings.HasPrefix(err.Error(), "TESTAPP_SERVER_PORT: ") {
+			t.Fatalf("Was expecting an error for TESTAPP_SERVER_PORT got %v", err)
+		}
+		var numErr *strconv.NumError
+		if !errors.As(err, &numErr) {
+			t.Fatalf("Was expecting the error to wrap a *strconv.NumError got %v", err)
+		}
+	})
+
+	t.Run("Validation", func(t *testing.T) {
+		var result TestEnv
+		err := FromEnv("TESTAPP_MISSING", &result)
+
+		var validationErrs ValidationErrors
+		if !errors.As(err, &validationErrs) || validationErrs[0].Key != "name" {
+			t.Fatalf("Was expecting a required error for name got %v", err)
+		}
+	})
+
+	t.Run("Not a struct", func(t *testing.T) {
+		var result string
+		if err := FromEnv("TESTAPP", &result); err == nil {
+			t.Fatal("Was expecting an error for a non-struct target")
+		}
+	})
+}
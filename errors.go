@@ -0,0 +1,50 @@
+package plain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// A DecodeError describes a value that could not be decoded.
+type DecodeError struct {
+	File string // included file the value is in, empty for the decoded input
+	Line int    // 1-based line number of the value
+	Key  string // key the value was set for
+	Err  error  // the underlying error
+}
+
+func (e *DecodeError) Error() string {
+	if e.File != "" {
+		return fmt.Sprintf("%s: line %d: %s: %s", e.File, e.Line, e.Key, e.Err)
+	}
+
+	return fmt.Sprintf("line %d: %s: %s", e.Line, e.Key, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// DecodeErrors lists every value that could not be decoded when a Decoder
+// collects errors.
+type DecodeErrors []*DecodeError
+
+func (e DecodeErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+
+	return strings.Join(messages, "\n")
+}
+
+// Unwrap returns the individual errors for errors.Is and errors.As.
+func (e DecodeErrors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, err := range e {
+		errs[i] = err
+	}
+
+	return errs
+}
@@ -0,0 +1,130 @@
+package plain
+
+import (
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// FormatOptions controls the output of FormatWithOptions.
+type FormatOptions struct {
+	// AlignKeys pads keys so the values of a record start in the same column.
+	AlignKeys bool
+
+	// SortKeys orders the keys of each record alphabetically. Comments move
+	// with the key that follows them.
+	SortKeys bool
+}
+
+// Format returns data in canonical form: one space after each key separator,
+// normalized lists and a single blank line between records. Comments and key
+// order are preserved. Formatting already formatted data returns it unchanged.
+func Format(data []byte) ([]byte, error) {
+	return FormatWithOptions(data, FormatOptions{})
+}
+
+// FormatWithOptions is like Format but applies the given options.
+func FormatWithOptions(data []byte, opts FormatOptions) ([]byte, error) {
+	doc, err := Parse(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var sb strings.Builder
+	for i, nodes := range doc.blocks() {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+
+		if opts.SortKeys {
+			nodes = sortNodes(nodes)
+		}
+
+		width := 0
+		if opts.AlignKeys {
+			for _, n := range nodes {
+				if n.Kind == KeyValueNode {
+					width = max(width, utf8.RuneCountInString(n.Key))
+				}
+			}
+		}
+
+		for _, n := range nodes {
+			sb.WriteString(formatNode(n, width))
+			sb.WriteString("\n")
+		}
+	}
+
+	return []byte(sb.String()), nil
+}
+
+// formatNode writes a node in canonical form, padding keys to width
+func formatNode(n *Node, width int) string {
+	if n.Kind == CommentNode {
+		return "#" + strings.TrimRight(n.Text, " \t\r")
+	}
+	if n.Kind == ValueNode {
+		return escapeContinued(n.Value)
+	}
+
+	value := formatValue(n.Value)
+	if value == "" {
+		return n.Key + ":"
+	}
+
+	padding := 1
+	if width > 0 {
+		padding += width - utf8.RuneCountInString(n.Key)
+	}
+
+	return escapeContinued(n.Key + ":" + strings.Repeat(" ", padding) + value)
+}
+
+// formatValue normalizes the spacing of list values
+func formatValue(value string) string {
+	if !strings.HasPrefix(value, "[") || !strings.HasSuffix(value, "]") {
+		return value
+	}
+
+	arrayContent := strings.TrimSpace(value[1 : len(value)-1])
+	if arrayContent == "" {
+		return "[]"
+	}
+
+	arrayElements := strings.Split(arrayContent, ",")
+	for i, arrayElement := range arrayElements {
+		arrayElements[i] = strings.TrimSpace(arrayElement)
+	}
+
+	return "[" + strings.Join(arrayElements, ", ") + "]"
+}
+
+// sortNodes orders key/value nodes by key, keeping each run of comments in
+// front of the key it precedes. Comments after the last key stay last.
+func sortNodes(nodes []*Node) []*Node {
+	type group struct {
+		key   string
+		nodes []*Node
+	}
+
+	var groups []group
+	var pending []*Node
+	for _, n := range nodes {
+		pending = append(pending, n)
+		if n.Kind == KeyValueNode {
+			groups = append(groups, group{key: n.Key, nodes: pending})
+			pending = nil
+		}
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		return groups[i].key < groups[j].key
+	})
+
+	sorted := make([]*Node, 0, len(nodes))
+	for _, g := range groups {
+		sorted = append(sorted, g.nodes...)
+	}
+
+	return append(sorted, pending...)
+}
@@ -0,0 +1,106 @@
+package plain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		opts     FormatOptions
+		expected string
+	}{
+		{
+			name:     "Spacing",
+			input:    "name:John Doe\n  age :  42  \nempty:   \n",
+			expected: "name: John Doe\nage: 42\nempty:\n",
+		},
+		{
+			name:     "Records",
+			input:    "\n\nname: John Doe\n\n\n\nname: Jane Doe\n\n",
+			expected: "name: John Doe\n\nname: Jane Doe\n",
+		},
+		{
+			name:     "Lists",
+			input:    "names: [a,b ,  c]\nempty: [ ]",
+			expected: "names: [a, b, c]\nempty: []\n",
+		},
+		{
+			name:     "Comments",
+			input:    "# header\n\n  # name comment   \nname: John Doe\n#trailing",
+			expected: "# header\n\n# name comment\nname: John Doe\n#trailing\n",
+		},
+		{
+			name:     "Carriage returns",
+			input:    "name: John Doe\r\nage: 42\r\n",
+			expected: "name: John Doe\nage: 42\n",
+		},
+		{
+			name:     "Align keys",
+			input:    "name: John Doe\nage: 42\n# comment\naddress.city: New York\nempty:\n\nid: 1",
+			opts:     FormatOptions{AlignKeys: true},
+			expected: "name:         John Doe\nage:          42\n# comment\naddress.city: New York\nempty:\n\nid: 1\n",
+		},
+		{
+			name:     "Sort keys",
+			input:    "name: John Doe\n# age comment\nage: 42\n# trailing",
+			opts:     FormatOptions{SortKeys: true},
+			expected: "# age comment\nage: 42\nname: John Doe\n# trailing\n",
+		},
+		{
+			name:     "Empty",
+			input:    "\n\n",
+			expected: "",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resp, err := FormatWithOptions([]byte(test.input), test.opts)
+			if err != nil {
+				t.Fatalf("Was not expecting an error got %s", err)
+			}
+			if string(resp) != test.expected {
+				t.Fatalf("Format was expecting %q\n got %q", test.expected, string(resp))
+			}
+
+			// Formatting again must not change anything
+			again, err := FormatWithOptions(resp, test.opts)
+			if err != nil {
+				t.Fatalf("Was not expecting an error got %s", err)
+			}
+			if string(again) != string(resp) {
+				t.Fatalf("Format is not idempotent, got %q then %q", string(resp), string(again))
+			}
+		})
+	}
+
+	t.Run("Values without keys", func(t *testing.T) {
+		data, err := Marshal([]int{1, 2})
+		if err != nil {
+			t.Fatalf("Was not expecting an error got %s", err)
+		}
+
+		formatted, err := Format(data)
+		if err != nil {
+			t.Fatalf("Was not expecting an error got %s", err)
+		}
+		if string(formatted) != "1\n\n2\n" {
+			t.Fatalf("Format was expecting %q got %q", "1\n\n2\n", formatted)
+		}
+	})
+
+	t.Run("Syntax error", func(t *testing.T) {
+		_, err := Format([]byte("name: John Doe\n: John Doe\n"))
+
+		var syntaxErr *SyntaxError
+		if !errors.As(err, &syntaxErr) {
+			t.Fatalf("Was expecting a SyntaxError got %v", err)
+		}
+		if syntaxErr.Line != 2 {
+			t.Fatalf("Was expecting the error on line 2 got %d", syntaxErr.Line)
+		}
+	})
+}
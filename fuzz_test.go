@@ -0,0 +1,230 @@
+package plain
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type TestFuzz struct {
+	Name     string            `plain:"name"`
+	Age      int               `plain:"age,default=1"`
+	Score    float64           `plain:"score"`
+	Active   bool              `plain:"active"`
+	Tags     []string          `plain:"tags,merge=append"`
+	Sub      TestFuzzSub       `plain:"sub"`
+	Ptr      *TestFuzzSub      `plain:"ptr"`
+	Labels   map[string]string `plain:"labels"`
+	Nested   []TestFuzzSub     `plain:"nested"`
+	Password string            `plain:"password,secret"`
+}
+
+type TestFuzzSub struct {
+	City  string `plain:"city,required"`
+	Count *int   `plain:"count"`
+}
+
+func FuzzUnmarshal(f *testing.F) {
+	seeds := []string{
+		"name: John Doe\nage: 42\nscore: 1.5\nactive: true\n",
+		"tags: [a, b]\ntags: [c]\nsub.city: Paris\nptr.city: Rome\nptr.count: 3\n",
+		"labels.env: prod\nnested: [city: a, city: b]\n",
+		"name: a\n\nname: b\n\n\n# comment\nname: c \\\n  d\n",
+		"a.b.c.d: [x, [y, z]]\n@include other.txt\n${name}: $${x}\n",
+		"| name | age |\n|---|---|\n| a | 1 |\n",
+		"[a, b]\n",
+		"ptr: <nil>\nsub: x\nsub.city: y\n",
+	}
+	for _, seed := range seeds {
+		f.Add([]byte(seed))
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		// Errors are fine, panics are not
+		var single TestFuzz
+		_ = Unmarshal(data, &single)
+
+		var records []TestFuzz
+		_ = Unmarshal(data, &records)
+
+		var generic map[string]any
+		_ = Unmarshal(data, &generic)
+
+		var maps []map[string]any
+		_ = Unmarshal(data, &maps)
+
+		dec := NewDecoder(bytes.NewReader(data))
+		dec.CollectErrors()
+		dec.Interpolate(nil)
+		dec.SetLimits(Limits{MaxListElements: 8, MaxKeyDepth: 4})
+		_ = dec.Decode(&records)
+
+		var table []TestFuzz
+		_ = UnmarshalTable(data, &table)
+
+		// What decodes must encode again
+		if _, err := Marshal(single); err != nil {
+			t.Fatalf("Marshal of decoded %+v failed: %s", single, err)
+		}
+		if _, err := Marshal(generic); err != nil {
+			t.Fatalf("Marshal of decoded %+v failed: %s", generic, err)
+		}
+	})
+}
+
+// roundTripTypes are the field types generated for round trip tests
+var roundTripTypes = []reflect.Type{
+	reflect.TypeOf(""),
+	reflect.TypeOf(0),
+	reflect.TypeOf(int64(0)),
+	reflect.TypeOf(0.0),
+	reflect.TypeOf(false),
+	reflect.TypeOf(time.Duration(0)),
+	reflect.TypeOf(time.Time{}),
+	reflect.TypeOf((*int)(nil)),
+	reflect.TypeOf([]string(nil)),
+	reflect.TypeOf([]int(nil)),
+	reflect.TypeOf(map[string]string(nil)), // last, see randomStruct
+}
+
+// randomStruct returns a struct type of tagged fields, nesting structs up
+// to depth levels
+func randomStruct(r *rand.Rand, depth int) reflect.Type {
+	fields := make([]reflect.StructField, 1+r.Intn(6))
+	for i := range fields {
+		typ := roundTripTypes[r.Intn(len(roundTripTypes))]
+		if i == 0 {
+			// Records without keys are skipped in a slice, so the first
+			// field is never an empty map or struct of maps
+			typ = roundTripTypes[r.Intn(len(roundTripTypes)-1)]
+		} else if depth > 0 && r.Intn(4) == 0 {
+			typ = randomStruct(r, depth-1)
+		}
+
+		fields[i] = reflect.StructField{
+			Name: fmt.Sprintf("F%d", i),
+			Type: typ,
+			Tag:  reflect.StructTag(fmt.Sprintf(`plain:"k%d"`, i)),
+		}
+	}
+
+	return reflect.StructOf(fields)
+}
+
+// randomString returns a string that reads back the same as a value or, in
+// a list, as an element
+func randomString(r *rand.Rand, element bool) string {
+	alphabet := "abcXYZ019_-./:#=@\\ "
+	if !element {
+		alphabet += ",[]"
+	}
+
+	b := make([]byte, r.Intn(12))
+	for i := range b {
+		b[i] = alphabet[r.Intn(len(alphabet))]
+	}
+	s := strings.TrimSpace(string(b))
+	if element && s == "" {
+		return "x"
+	}
+
+	return s
+}
+
+// fillRandom sets v to a random value that Marshal writes in full
+func fillRandom(r *rand.Rand, v reflect.Value) {
+	switch {
+	case v.Type() == timeType:
+		v.Set(reflect.ValueOf(time.Unix(r.Int63n(1<<32), r.Int63n(1e9)).UTC()))
+	case v.Type() == durationType:
+		v.SetInt(r.Int63n(int64(1000 * time.Hour)))
+	}
+	if v.Type() == timeType || v.Type() == durationType {
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(randomString(r, false))
+	case reflect.Int, reflect.Int64:
+		v.SetInt(r.Int63() - r.Int63())
+	case reflect.Float64:
+		v.SetFloat(r.NormFloat64() * 1e6)
+	case reflect.Bool:
+		v.SetBool(r.Intn(2) == 0)
+	case reflect.Ptr:
+		if r.Intn(3) > 0 {
+			v.Set(reflect.New(v.Type().Elem()))
+			fillRandom(r, v.Elem())
+		}
+	case reflect.Slice:
+		// Empty lists decode as nil
+		n := r.Intn(4)
+		if n == 0 {
+			return
+		}
+		v.Set(reflect.MakeSlice(v.Type(), n, n))
+		for i := 0; i < n; i++ {
+			if v.Index(i).Kind() == reflect.String {
+				v.Index(i).SetString(randomString(r, true))
+			} else {
+				fillRandom(r, v.Index(i))
+			}
+		}
+	case reflect.Map:
+		// Empty maps write no keys and decode as nil
+		n := r.Intn(3)
+		if n == 0 {
+			return
+		}
+		v.Set(reflect.MakeMap(v.Type()))
+		for i := 0; i < n; i++ {
+			v.SetMapIndex(reflect.ValueOf(fmt.Sprintf("m%d", i)), reflect.ValueOf(randomString(r, false)))
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			fillRandom(r, v.Field(i))
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 500; i++ {
+		typ := randomStruct(r, 2)
+		records := reflect.MakeSlice(reflect.SliceOf(typ), 1+r.Intn(3), 3)
+		for j := 0; j < records.Len(); j++ {
+			fillRandom(r, records.Index(j))
+		}
+
+		// A single record
+		data, err := Marshal(records.Index(0).Interface())
+		if err != nil {
+			t.Fatalf("Was not expecting an error got %s", err)
+		}
+		single := reflect.New(typ)
+		if err := Unmarshal(data, single.Interface()); err != nil {
+			t.Fatalf("Unmarshal of %q failed: %s", data, err)
+		}
+		if !reflect.DeepEqual(records.Index(0).Interface(), single.Elem().Interface()) {
+			t.Fatalf("Round trip of %q was expecting %+v\n got %+v", data, records.Index(0), single.Elem())
+		}
+
+		// A slice of records
+		data, err = Marshal(records.Interface())
+		if err != nil {
+			t.Fatalf("Was not expecting an error got %s", err)
+		}
+		slice := reflect.New(records.Type())
+		if err := Unmarshal(data, slice.Interface()); err != nil {
+			t.Fatalf("Unmarshal of %q failed: %s", data, err)
+		}
+		if !reflect.DeepEqual(records.Interface(), slice.Elem().Interface()) {
+			t.Fatalf("Round trip of %q was expecting %+v\n got %+v", data, records, slice.Elem())
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package plain
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+)
+
+// includeDirective starts a line that includes another file
+const includeDirective = "@include"
+
+// ErrIncludeCycle is returned when a file includes itself, directly or
+// through other files.
+var ErrIncludeCycle = errors.New("include cycle")
+
+// includeTarget returns the path of an @include line
+func includeTarget(line string) (string, bool) {
+	rest, ok := strings.CutPrefix(strings.TrimSpace(line), includeDirective)
+	if !ok || rest == "" || (rest[0] != ' ' && rest[0] != '\t') {
+		return "", false
+	}
+
+	return strings.TrimSpace(rest), true
+}
+
+// include reads the pairs of the file target, relative to the file that
+// includes it
+func (d *decodeState) include(file, target string, stack []string) ([]pair, error) {
+	if path.IsAbs(target) {
+		return nil, errors.New("include path must be relative: " + target)
+	}
+
+	name := path.Join(path.Dir(file), target)
+	if !fs.ValidPath(name) {
+		return nil, errors.New("include path outside the file system: " + target)
+	}
+	if name == file || slices.Contains(stack, name) {
+		return nil, fmt.Errorf("%w: %s", ErrIncludeCycle, strings.Join(append(slices.Clip(stack), name), " -> "))
+	}
+
+	data, err := fs.ReadFile(d.fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	if err := d.checkInput(name, data); err != nil {
+		return nil, err
+	}
+
+	return d.readPairs(name, data, 1, append(slices.Clip(stack), name))
+}
@@ -0,0 +1,104 @@
+package plain
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+type TestIncluded struct {
+	Name string `plain:"name"`
+	Host string `plain:"db.host"`
+	Port int    `plain:"db.port"`
+}
+
+func decodeIncluded(data string, fsys fstest.MapFS, v any) error {
+	dec := NewDecoder(strings.NewReader(data))
+	dec.IncludeFS(fsys)
+	return dec.Decode(v)
+}
+
+func TestInclude(t *testing.T) {
+	fsys := fstest.MapFS{
+		"base.txt":         {Data: []byte("name: base\ndb.host: localhost\ndb.port: 5432\n")},
+		"conf/db.txt":      {Data: []byte("@include ../base.txt\ndb.host: db.internal\n")},
+		"conf/bad.txt":     {Data: []byte("db.port: nope\n")},
+		"cycle/a.txt":      {Data: []byte("@include b.txt\n")},
+		"cycle/b.txt":      {Data: []byte("@include a.txt\n")},
+		"conf/escape.txt":  {Data: []byte("@include ../../secret.txt\n")},
+		"conf/missing.txt": {Data: []byte("@include nope.txt\n")},
+	}
+
+	t.Run("Override", func(t *testing.T) {
+		var result TestIncluded
+		if err := decodeIncluded("@include conf/db.txt\nname: app\n", fsys, &result); err != nil {
+			t.Fatalf("Was not expecting an error got %s", err)
+		}
+
+		expected := TestIncluded{Name: "app", Host: "db.internal", Port: 5432}
+		if result != expected {
+			t.Fatalf("Include was expecting %+v\n got %+v", expected, result)
+		}
+	})
+
+	t.Run("Included later", func(t *testing.T) {
+		var result TestIncluded
+		if err := decodeIncluded("name: app\n@include base.txt\n", fsys, &result); err != nil {
+			t.Fatalf("Was not expecting an error got %s", err)
+		}
+		if result.Name != "base" {
+			t.Fatalf("Include was expecting the included name to win got %q", result.Name)
+		}
+	})
+
+	t.Run("Error in included file", func(t *testing.T) {
+		var result TestIncluded
+		err := decodeIncluded("name: app\n@include conf/bad.txt\n", fsys, &result)
+
+		var decodeErr *DecodeError
+		if !errors.As(err, &decodeErr) || decodeErr.File != "conf/bad.txt" || decodeErr.Line != 1 {
+			t.Fatalf("Was expecting a DecodeError in conf/bad.txt on line 1 got %v", err)
+		}
+	})
+
+	t.Run("Cycle", func(t *testing.T) {
+		var result TestIncluded
+		err := decodeIncluded("@include cycle/a.txt", fsys, &result)
+		if !errors.Is(err, ErrIncludeCycle) {
+			t.Fatalf("Was expecting ErrIncludeCycle got %v", err)
+		}
+	})
+
+	t.Run("Traversal", func(t *testing.T) {
+		for _, data := range []string{"@include ../secret.txt", "@include conf/escape.txt", "@include /etc/passwd"} {
+			var result TestIncluded
+			err := decodeIncluded(data, fsys, &result)
+
+			var decodeErr *DecodeError
+			if !errors.As(err, &decodeErr) || decodeErr.Key != "@include" {
+				t.Fatalf("Was expecting an include error for %q got %v", data, err)
+			}
+		}
+	})
+
+	t.Run("Missing", func(t *testing.T) {
+		var result TestIncluded
+		err := decodeIncluded("@include conf/missing.txt", fsys, &result)
+
+		var decodeErr *DecodeError
+		if !errors.As(err, &decodeErr) || decodeErr.File != "conf/missing.txt" {
+			t.Fatalf("Was expecting an include error in conf/missing.txt got %v", err)
+		}
+	})
+
+	t.Run("Without file system", func(t *testing.T) {
+		var result TestIncluded
+		if err := Unmarshal([]byte("@include base.txt\nname: app"), &result); err != nil {
+			t.Fatalf("Was not expecting an error got %s", err)
+		}
+		if result.Name != "app" || result.Host != "" {
+			t.Fatalf("Unmarshal was expecting the include skipped got %+v", result)
+		}
+	})
+}
@@ -0,0 +1,125 @@
+package plain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrUndefinedReference is returned when a ${key} or ${env:NAME}
+	// reference names a key or variable that does not exist.
+	ErrUndefinedReference = errors.New("undefined reference")
+
+	// ErrReferenceCycle is returned when a key refers to itself before it
+	// has a value.
+	ErrReferenceCycle = errors.New("reference cycle")
+)
+
+// interpolation expands the values of a single record in order
+type interpolation struct {
+	lookup  func(string) (string, bool)
+	defined map[string]string // lower case key to its expanded value so far
+	later   map[string]int    // lower case key to its definitions further down
+	key     string            // lower case key of the value being expanded
+}
+
+// expand replaces references in the values of a record. References name
+// keys defined above them and get the value the key has at that point, a
+// key defined again later does not change it.
+func (d *decodeState) expand(pairs []pair) ([]pair, error) {
+	in := &interpolation{
+		lookup:  d.lookup,
+		defined: map[string]string{},
+		later:   map[string]int{},
+	}
+	for _, p := range pairs {
+		in.later[strings.ToLower(p.key)]++
+	}
+
+	expanded := make([]pair, 0, len(pairs))
+	for _, p := range pairs {
+		key := strings.ToLower(p.key)
+		in.key = key
+		in.later[key]--
+
+		value, err := in.replace(p.value)
+		if err != nil {
+			if err := d.fail(p, err); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		p.value = value
+		in.defined[key] = value
+		expanded = append(expanded, p)
+	}
+
+	return expanded, nil
+}
+
+// replace expands every reference in value
+func (in *interpolation) replace(value string) (string, error) {
+	if !strings.Contains(value, "${") {
+		return value, nil
+	}
+
+	var sb strings.Builder
+	for {
+		start := strings.Index(value, "${")
+		if start < 0 {
+			sb.WriteString(value)
+			return sb.String(), nil
+		}
+
+		// $${ is an escaped ${
+		if start > 0 && value[start-1] == '$' {
+			sb.WriteString(value[:start])
+			sb.WriteString("{")
+			value = value[start+2:]
+			continue
+		}
+
+		end := strings.IndexByte(value[start:], '}')
+		if end < 0 {
+			return "", errors.New("missing } in reference: " + value[start:])
+		}
+		end += start
+
+		resolved, err := in.resolve(strings.TrimSpace(value[start+2 : end]))
+		if err != nil {
+			return "", err
+		}
+
+		sb.WriteString(value[:start])
+		sb.WriteString(resolved)
+		value = value[end+1:]
+	}
+}
+
+// resolve returns the value of a single reference
+func (in *interpolation) resolve(name string) (string, error) {
+	if env, ok := strings.CutPrefix(name, "env:"); ok {
+		if in.lookup != nil {
+			if value, ok := in.lookup(env); ok {
+				return value, nil
+			}
+		}
+
+		return "", fmt.Errorf("%w: %s", ErrUndefinedReference, name)
+	}
+
+	key := strings.ToLower(name)
+	if value, ok := in.defined[key]; ok {
+		return value, nil
+	}
+	switch {
+	case key == in.key:
+		return "", fmt.Errorf("%w: %s", ErrReferenceCycle, name)
+	case in.later[key] > 0:
+		return "", fmt.Errorf("%w: %s is defined after it is used", ErrUndefinedReference, name)
+	}
+
+	return "", fmt.Errorf("%w: %s", ErrUndefinedReference, name)
+}
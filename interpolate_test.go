@@ -0,0 +1,141 @@
+package plain
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type TestInterpolated struct {
+	Host string   `plain:"host"`
+	URL  string   `plain:"url"`
+	Dirs []string `plain:"dirs"`
+	Sub  struct {
+		Path string `plain:"path"`
+	} `plain:"sub"`
+}
+
+func decodeInterpolated(data string, v any, lookup func(string) (string, bool)) error {
+	dec := NewDecoder(strings.NewReader(data))
+	dec.Interpolate(lookup)
+	return dec.Decode(v)
+}
+
+func TestInterpolate(t *testing.T) {
+	env := func(name string) (string, bool) {
+		if name == "HOME" {
+			return "/home/test", true
+		}
+		return "", false
+	}
+
+	t.Run("References", func(t *testing.T) {
+		var result TestInterpolated
+		data := "host: example.com\nsub.path: ${env:HOME}/api\nurl: https://${host}/${ sub.path }\ndirs: [${env:HOME}, $${literal}]\n"
+		if err := decodeInterpolated(data, &result, env); err != nil {
+			t.Fatalf("Was not expecting an error got %s", err)
+		}
+
+		if result.URL != "https://example.com//home/test/api" {
+			t.Fatalf("Interpolate was expecting the url expanded got %q", result.URL)
+		}
+		if result.Sub.Path != "/home/test/api" {
+			t.Fatalf("Interpolate was expecting the path expanded got %q", result.Sub.Path)
+		}
+		if !reflect.DeepEqual(result.Dirs, []string{"/home/test", "${literal}"}) {
+			t.Fatalf("Interpolate was expecting the list expanded got %v", result.Dirs)
+		}
+	})
+
+	t.Run("Per record", func(t *testing.T) {
+		var result []map[string]any
+		data := "host: a\nurl: ${host}\n\nhost: b\nurl: ${HOST}\n"
+		if err := decodeInterpolated(data, &result, nil); err != nil {
+			t.Fatalf("Was not expecting an error got %s", err)
+		}
+
+		if result[0]["url"] != "a" || result[1]["url"] != "b" {
+			t.Fatalf("Interpolate was expecting a and b got %v", result)
+		}
+	})
+
+	t.Run("Undefined", func(t *testing.T) {
+		var result TestInterpolated
+		err := decodeInterpolated("host: a\nurl: ${missing}", &result, nil)
+
+		var decodeErr *DecodeError
+		if !errors.As(err, &decodeErr) || decodeErr.Line != 2 || decodeErr.Key != "url" {
+			t.Fatalf("Was expecting a DecodeError for url on line 2 got %v", err)
+		}
+		if !errors.Is(err, ErrUndefinedReference) {
+			t.Fatalf("Was expecting ErrUndefinedReference got %v", err)
+		}
+	})
+
+	t.Run("Undefined env", func(t *testing.T) {
+		var result TestInterpolated
+		err := decodeInterpolated("host: ${env:NOPE}", &result, env)
+		if !errors.Is(err, ErrUndefinedReference) {
+			t.Fatalf("Was expecting ErrUndefinedReference got %v", err)
+		}
+	})
+
+	t.Run("Redefined", func(t *testing.T) {
+		var result map[string]any
+		data := "a: 1\nb: ${a}\na: ${a}2\nc: ${a}\n"
+		if err := decodeInterpolated(data, &result, nil); err != nil {
+			t.Fatalf("Was not expecting an error got %s", err)
+		}
+
+		if result["b"] != "1" || result["a"] != "12" || result["c"] != "12" {
+			t.Fatalf("Interpolate was expecting the values above each reference got %v", result)
+		}
+	})
+
+	t.Run("Forward", func(t *testing.T) {
+		var result TestInterpolated
+		err := decodeInterpolated("url: ${host}\nhost: a", &result, nil)
+
+		var decodeErr *DecodeError
+		if !errors.As(err, &decodeErr) || decodeErr.Line != 1 || !errors.Is(err, ErrUndefinedReference) {
+			t.Fatalf("Was expecting ErrUndefinedReference on line 1 got %v", err)
+		}
+		if !strings.Contains(err.Error(), "host is defined after it is used") {
+			t.Fatalf("Was expecting a forward reference error got %q", err)
+		}
+	})
+
+	t.Run("Cycle", func(t *testing.T) {
+		var result TestInterpolated
+		err := decodeInterpolated("host: ${host}", &result, nil)
+		if !errors.Is(err, ErrReferenceCycle) {
+			t.Fatalf("Was expecting ErrReferenceCycle got %v", err)
+		}
+	})
+
+	t.Run("Collect", func(t *testing.T) {
+		var result TestInterpolated
+		dec := NewDecoder(strings.NewReader("host: ${host}\nurl: ${nope}\nsub.path: ok"))
+		dec.Interpolate(nil)
+		dec.CollectErrors()
+
+		var decodeErrs DecodeErrors
+		if err := dec.Decode(&result); !errors.As(err, &decodeErrs) || len(decodeErrs) != 2 {
+			t.Fatalf("Was expecting 2 DecodeErrors got %v", err)
+		}
+		if result.Sub.Path != "ok" {
+			t.Fatalf("Was expecting the other keys to be set got %+v", result)
+		}
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		var result TestInterpolated
+		if err := Unmarshal([]byte("url: ${host}"), &result); err != nil {
+			t.Fatalf("Was not expecting an error got %s", err)
+		}
+		if result.URL != "${host}" {
+			t.Fatalf("Unmarshal was expecting the reference kept got %q", result.URL)
+		}
+	})
+}
@@ -0,0 +1,102 @@
+package plain
+
+import (
+	"bytes"
+	"fmt"
+	"unicode/utf8"
+)
+
+// Limits bound the resources a Decoder spends on its input, for decoding
+// payloads from untrusted clients. A zero field is no limit.
+//
+// MaxKeyDepth bounds the same depth as Decoder.SetMaxDepth, counting the
+// segments of a key and the list records it is nested in, and the lower of
+// the two wins. Input deeper than MaxKeyDepth fails with a *LimitError when
+// it is the lower one, and with ErrMaxDepth otherwise.
+type Limits struct {
+	MaxBytes        int64 // bytes of input, included files counted separately
+	MaxLineLength   int   // characters on a line
+	MaxRecords      int   // records in a slice of records or a table
+	MaxListElements int   // elements of a single list value
+	MaxKeyDepth     int   // depth of a key, so a.b.c has a depth of 3
+}
+
+// A LimitError is returned when input exceeds one of the Limits of a
+// Decoder. Decoding stops at the first one, even when errors are collected.
+type LimitError struct {
+	Limit string // name of the Limits field that was exceeded
+	Max   int64  // value of the limit
+	File  string // included file the limit was exceeded in, empty for the decoded input
+	Line  int    // 1-based line number the limit was exceeded on, 0 for none
+}
+
+func (e *LimitError) Error() string {
+	switch {
+	case e.File != "" && e.Line > 0:
+		return fmt.Sprintf("%s: line %d: input exceeds %s of %d", e.File, e.Line, e.Limit, e.Max)
+	case e.File != "":
+		return fmt.Sprintf("%s: input exceeds %s of %d", e.File, e.Limit, e.Max)
+	case e.Line > 0:
+		return fmt.Sprintf("line %d: input exceeds %s of %d", e.Line, e.Limit, e.Max)
+	}
+
+	return fmt.Sprintf("input exceeds %s of %d", e.Limit, e.Max)
+}
+
+// checkInput checks the size and line lengths of the input read from file
+func (d *decodeState) checkInput(file string, data []byte) error {
+	limits := d.limits
+	if limits.MaxBytes > 0 && int64(len(data)) > limits.MaxBytes {
+		return &LimitError{Limit: "MaxBytes", Max: limits.MaxBytes, File: file}
+	}
+	if limits.MaxLineLength <= 0 {
+		return nil
+	}
+
+	line := 1
+	for len(data) > 0 {
+		text := data
+		if end := bytes.IndexByte(data, '\n'); end >= 0 {
+			text, data = data[:end], data[end+1:]
+		} else {
+			data = nil
+		}
+
+		if utf8.RuneCount(bytes.TrimRight(text, "\r")) > limits.MaxLineLength {
+			return &LimitError{Limit: "MaxLineLength", Max: int64(limits.MaxLineLength), File: file, Line: line}
+		}
+		line++
+	}
+
+	return nil
+}
+
+// checkRecords checks that one more record can be decoded, line being the
+// line number of its first line
+func (d *decodeState) checkRecords(line int) error {
+	if limit := d.limits.MaxRecords; limit > 0 && d.record >= limit {
+		return &LimitError{Limit: "MaxRecords", Max: int64(limit), Line: line}
+	}
+
+	return nil
+}
+
+// checkKeyDepth returns a *LimitError when MaxKeyDepth is what bounds the
+// depth of a key read from a line of file
+func (d *decodeState) checkKeyDepth(file string, line int) error {
+	if limit := d.limits.MaxKeyDepth; limit > 0 && limit == d.maxDepth {
+		return &LimitError{Limit: "MaxKeyDepth", Max: int64(limit), File: file, Line: line}
+	}
+
+	return nil
+}
+
+// checkListElements checks that a list may have count elements, checked
+// as the list is split
+func (d *decodeState) checkListElements(count int) error {
+	if limit := d.limits.MaxListElements; limit > 0 && count > limit {
+		return &LimitError{Limit: "MaxListElements", Max: int64(limit)}
+	}
+
+	return nil
+}
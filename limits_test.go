@@ -0,0 +1,108 @@
+package plain
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+type TestLimits struct {
+	Name   string       `plain:"name"`
+	Tags   []string     `plain:"tags"`
+	Groups []TestLimits `plain:"groups"`
+}
+
+func TestDecodeLimits(t *testing.T) {
+	decode := func(input string, limits Limits, v any) error {
+		dec := NewDecoder(strings.NewReader(input))
+		dec.SetLimits(limits)
+		return dec.Decode(v)
+	}
+
+	tests := []struct {
+		name   string
+		input  string
+		limits Limits
+		limit  string
+		line   int
+	}{
+		{"Bytes", "name: John Doe\n", Limits{MaxBytes: 8}, "MaxBytes", 0},
+		{"Line length", "name: ok\nname: John Doe\n", Limits{MaxLineLength: 10}, "MaxLineLength", 2},
+		{"Records", "name: a\n\nname: b\n\nname: c\n", Limits{MaxRecords: 2}, "MaxRecords", 5},
+		{"List elements", "name: a\ntags: [a, b, c]\n", Limits{MaxListElements: 2}, "MaxListElements", 2},
+		{"Key depth", "name: a\nsub.name.first: b\n", Limits{MaxKeyDepth: 2}, "MaxKeyDepth", 2},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var result []TestLimits
+			err := decode(test.input, test.limits, &result)
+
+			var limitErr *LimitError
+			if !errors.As(err, &limitErr) {
+				t.Fatalf("Decode was expecting a limit error got %v", err)
+			}
+			if limitErr.Limit != test.limit || limitErr.Line != test.line {
+				t.Fatalf("Decode was expecting %s on line %d got %s on line %d", test.limit, test.line, limitErr.Limit, limitErr.Line)
+			}
+		})
+	}
+
+	t.Run("Within limits", func(t *testing.T) {
+		limits := Limits{MaxBytes: 64, MaxLineLength: 20, MaxRecords: 2, MaxListElements: 3, MaxKeyDepth: 1}
+
+		var result []TestLimits
+		if err := decode("name: a\ntags: [a, b, c]\n\nname: b\n", limits, &result); err != nil {
+			t.Fatalf("Was not expecting an error got %s", err)
+		}
+		if len(result) != 2 || len(result[0].Tags) != 3 {
+			t.Fatalf("Decode got %+v", result)
+		}
+	})
+
+	t.Run("Nested lists", func(t *testing.T) {
+		var result []TestLimits
+		if err := decode("groups: [tags: [a, b, c], tags: [d]]\n", Limits{MaxListElements: 3}, &result); err != nil {
+			t.Fatalf("Was not expecting an error got %s", err)
+		}
+		if len(result) != 1 || len(result[0].Groups) != 2 || len(result[0].Groups[0].Tags) != 3 {
+			t.Fatalf("Decode got %+v", result)
+		}
+	})
+
+	t.Run("Max depth", func(t *testing.T) {
+		dec := NewDecoder(strings.NewReader("sub.name.first: b\n"))
+		dec.SetMaxDepth(2)
+		dec.SetLimits(Limits{MaxKeyDepth: 4})
+
+		var result map[string]any
+		if err := dec.Decode(&result); !errors.Is(err, ErrMaxDepth) {
+			t.Fatalf("Decode was expecting ErrMaxDepth got %v", err)
+		}
+	})
+
+	t.Run("Collected errors", func(t *testing.T) {
+		dec := NewDecoder(strings.NewReader("tags: [a, b, c]\n"))
+		dec.CollectErrors()
+		dec.SetLimits(Limits{MaxListElements: 2})
+
+		var result map[string]any
+		var limitErr *LimitError
+		if err := dec.Decode(&result); !errors.As(err, &limitErr) || err.Error() != "line 1: input exceeds MaxListElements of 2" {
+			t.Fatalf("Decode was expecting a limit error got %v", err)
+		}
+	})
+
+	t.Run("Included file", func(t *testing.T) {
+		fsys := fstest.MapFS{"base.txt": {Data: []byte("name: a much longer line\n")}}
+		dec := NewDecoder(strings.NewReader("@include base.txt\n"))
+		dec.IncludeFS(fsys)
+		dec.SetLimits(Limits{MaxLineLength: 20})
+
+		var result TestLimits
+		err := dec.Decode(&result)
+		if err == nil || err.Error() != "base.txt: line 1: input exceeds MaxLineLength of 20" {
+			t.Fatalf("Decode was expecting a limit error in base.txt got %v", err)
+		}
+	})
+}
@@ -0,0 +1,93 @@
+package plain
+
+import (
+	"reflect"
+	"strings"
+	"unicode/utf8"
+)
+
+// MarshalMarkdown writes v as a Markdown table. A slice of structs or maps
+// gets a column per key and a row per element, any other value a row per
+// key with its value. Pipes are escaped and line breaks become <br>.
+func MarshalMarkdown(v any) ([]byte, error) {
+	return newEncodeState(encodeOptions{layout: LayoutMarkdown}).marshal(v)
+}
+
+// marshalMarkdown writes a slice of records or a single record as a table
+func (e *encodeState) marshalMarkdown(val reflect.Value) ([]byte, error) {
+	var header []string
+	var rows [][]string
+	if val.Kind() == reflect.Slice {
+		columns, cells, err := e.tableCells(val, true)
+		if err != nil {
+			return nil, err
+		}
+
+		header = columns
+		for _, record := range cells {
+			values := make([]string, len(columns))
+			for i, column := range columns {
+				values[i] = record[column]
+			}
+			rows = append(rows, values)
+		}
+	} else {
+		var record []row
+		if err := e.plainStruct(&record, val, ""); err != nil {
+			return nil, err
+		}
+
+		header = []string{"Key", "Value"}
+		for _, r := range record {
+			rows = append(rows, []string{e.formatKey(r.key), r.value})
+		}
+	}
+
+	for i := range header {
+		header[i] = escapeMarkdown(header[i])
+	}
+	for _, values := range rows {
+		for i := range values {
+			values[i] = escapeMarkdown(values[i])
+		}
+	}
+
+	// Pad columns so the source reads as a table too
+	widths := make([]int, len(header))
+	for i, column := range header {
+		widths[i] = max(3, utf8.RuneCountInString(column))
+		for _, values := range rows {
+			widths[i] = max(widths[i], utf8.RuneCountInString(values[i]))
+		}
+	}
+
+	divider := make([]string, len(header))
+	for i, width := range widths {
+		divider[i] = strings.Repeat("-", width)
+	}
+
+	lines := make([]string, 0, len(rows)+2)
+	lines = append(lines, markdownLine(header, widths), markdownLine(divider, widths))
+	for _, values := range rows {
+		lines = append(lines, markdownLine(values, widths))
+	}
+
+	return []byte(strings.Join(lines, "\n")), nil
+}
+
+// markdownLine writes the cells of a table row padded to their widths
+func markdownLine(values []string, widths []int) string {
+	var sb strings.Builder
+	sb.WriteString("|")
+	for i, value := range values {
+		sb.WriteString(" " + value + strings.Repeat(" ", widths[i]-utf8.RuneCountInString(value)) + " |")
+	}
+
+	return sb.String()
+}
+
+// escapeMarkdown keeps a value inside its table cell
+func escapeMarkdown(value string) string {
+	value = strings.ReplaceAll(value, "\r\n", "\n")
+	return strings.NewReplacer("|", `\|`, "\n", "<br>", "\r", "<br>").Replace(strings.TrimRight(value, "\n"))
+}
@@ -0,0 +1,68 @@
+package plain
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestMarshalMarkdown(t *testing.T) {
+	t.Run("Record", func(t *testing.T) {
+		resp, err := MarshalMarkdown(TestTable{Name: "a|b", Age: 42, Address: TestTableAddress{City: "New\nYork"}})
+		if err != nil {
+			t.Fatalf("Was not expecting an error got %s", err)
+		}
+
+		expected := strings.Join([]string{
+			"| Key          | Value       |",
+			"| ------------ | ----------- |",
+			"| name         | a\\|b        |",
+			"| age          | 42          |",
+			"| active       | false       |",
+			"| address.city | New<br>York |",
+		}, "\n")
+		if string(resp) != expected {
+			t.Fatalf("MarshalMarkdown was expecting %q\n got %q", expected, resp)
+		}
+	})
+
+	t.Run("Slice", func(t *testing.T) {
+		resp, err := MarshalMarkdown([]TestTable{{Name: "John", Age: 42, Active: true}, {Name: "Jane"}})
+		if err != nil {
+			t.Fatalf("Was not expecting an error got %s", err)
+		}
+
+		expected := strings.Join([]string{
+			"| name | age | active | address.city |",
+			"| ---- | --- | ------ | ------------ |",
+			"| John | 42  | true   |              |",
+			"| Jane | 0   | false  |              |",
+		}, "\n")
+		if string(resp) != expected {
+			t.Fatalf("MarshalMarkdown was expecting %q\n got %q", expected, resp)
+		}
+	})
+
+	t.Run("Encoder", func(t *testing.T) {
+		var buf bytes.Buffer
+		enc := NewEncoder(&buf)
+		enc.SetLayout(LayoutMarkdown)
+		if err := enc.Encode(map[string]string{"a": "1"}); err != nil {
+			t.Fatalf("Was not expecting an error got %s", err)
+		}
+
+		expected := "| Key | Value |\n| --- | ----- |\n| a   | 1     |\n"
+		if buf.String() != expected {
+			t.Fatalf("Encoder was expecting %q\n got %q", expected, buf.String())
+		}
+	})
+
+	t.Run("Not decodable", func(t *testing.T) {
+		var result []TestTable
+		dec := NewDecoder(strings.NewReader("| name |\n| --- |\n| a |"))
+		dec.SetLayout(LayoutMarkdown)
+		if err := dec.Decode(&result); err == nil {
+			t.Fatal("Was expecting an error decoding markdown")
+		}
+	})
+}
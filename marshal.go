@@ -1,36 +1,104 @@
 package plain
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
+	"sort"
 	"strings"
 	"time"
+	"unicode/utf8"
 )
 
 type Marshaler interface {
 	MarshalPlain() ([]byte, error)
 }
 
+// Marshal returns the plain encoding of data using DialectDefault.
 func Marshal(data any) ([]byte, error) {
+	return newEncodeState(encodeOptions{}).marshal(data)
+}
+
+// encodeOptions are the settings of an Encoder
+type encodeOptions struct {
+	dialect Dialect
+	align   bool // pad keys so the values of a record line up
+	wrap    int  // wrap values at this many columns, 0 to not wrap
+	layout  Layout
+	theme   *Theme // colors of keys and values, nil for no color
+
+	sortKeys bool // write struct fields by tag name instead of declaration order
+	maxDepth int  // deepest a value may nest, 0 for DefaultMaxDepth
+
+	redact Redactor // redactor of secret fields without a form, nil to mask
+	reveal bool     // write secret fields as they are
+}
+
+// encodeState holds the options of a single encode
+type encodeState struct {
+	encodeOptions
+
+	depth    int            // levels of structs, maps and slices being encoded
+	visiting map[visit]bool // pointers and maps being encoded
+	header   *tableHeader   // header of the table being continued, if any
+}
+
+func newEncodeState(opts encodeOptions) *encodeState {
+	if opts.dialect == (Dialect{}) {
+		opts.dialect = DialectDefault
+	}
+	if opts.maxDepth <= 0 {
+		opts.maxDepth = DefaultMaxDepth
+	}
+
+	return &encodeState{encodeOptions: opts}
+}
+
+// row is a key and value to write on a line
+type row struct {
+	key   string
+	value string
+	kind  valueKind
+}
+
+func (e *encodeState) marshal(data any) ([]byte, error) {
 	var sb strings.Builder
 	val := reflect.ValueOf(data)
+
+	// A root record keeps its pointer, so a cycle back to it is found
+	// where it closes
+	root := val
 	if val.Kind() == reflect.Ptr {
 		val = val.Elem()
+		if !val.IsValid() || val.Kind() == reflect.Slice {
+			root = val
+		}
+	}
+	switch e.layout {
+	case LayoutTable:
+		return e.marshalTable(val)
+	case LayoutMarkdown:
+		return e.marshalMarkdown(val)
 	}
 	if val.Kind() == reflect.Slice {
+		records := make([]string, 0, val.Len())
 		for i := 0; i < val.Len(); i++ {
-			err := plainStruct(&sb, val.Index(i), "")
+			var rows []row
+			err := e.plainStruct(&rows, val.Index(i), "")
 			if err != nil {
 				return nil, err
 			}
 
-			sb.WriteString("\n")
+			records = append(records, strings.TrimRight(e.render(rows), "\n"))
 		}
+		sb.WriteString(strings.Join(records, e.dialect.RecordSeparator))
 	} else {
-		err := plainStruct(&sb, val, "")
+		var rows []row
+		err := e.plainStruct(&rows, root, "")
 		if err != nil {
 			return nil, err
 		}
+		sb.WriteString(e.render(rows))
 	}
 
 	buildStr := sb.String()
@@ -43,18 +111,58 @@ func Marshal(data any) ([]byte, error) {
 	return []byte(buildStr), nil
 }
 
-func plainStruct(sb *strings.Builder, val reflect.Value, parent string) error {
+func (e *encodeState) plainStruct(rows *[]row, val reflect.Value, parent string) error {
+	// A nil value passed to Marshal, or a nil pointer to it, has no keys
+	if !val.IsValid() {
+		return nil
+	}
 	typ := val.Type()
 
 	// switch on the type of the value
 	switch val.Kind() {
 	case reflect.Ptr:
-		// if the value is a pointer, dereference it
-		val = val.Elem()
-		err := plainStruct(sb, val, parent)
-		if err != nil {
+		if val.IsNil() {
+			e.addRow(rows, parent, nil)
+			return nil
+		}
+
+		// if the value is a pointer, dereference it unless it points back up
+		if err := e.enter(parent, val); err != nil {
+			return err
+		}
+		defer e.leave(val)
+
+		return e.plainStruct(rows, val.Elem(), parent)
+	case reflect.Interface:
+		// if the value is an interface, marshal what it holds
+		if val.IsNil() {
+			e.addRow(rows, parent, nil)
+			return nil
+		}
+
+		return e.plainStruct(rows, val.Elem(), parent)
+	case reflect.Map:
+		if err := e.enter(parent, val); err != nil {
+			return err
+		}
+		defer e.leave(val)
+		if err := e.descend(parent); err != nil {
 			return err
 		}
+		defer func() { e.depth-- }()
+
+		// if the value is a map, loop over its keys in sorted order
+		keys := val.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+		})
+
+		for _, key := range keys {
+			err := e.plainStruct(rows, val.MapIndex(key), e.joinKey(parent, fmt.Sprint(key.Interface())))
+			if err != nil {
+				return err
+			}
+		}
 
 		return nil
 	case reflect.Struct:
@@ -66,115 +174,232 @@ func plainStruct(sb *strings.Builder, val reflect.Value, parent string) error {
 				return err
 			}
 
-			sb.WriteString(rowOutput(parent, string(marshaled)))
+			e.addRow(rows, parent, string(marshaled))
 			return nil
 		}
 
-		// if the value is a struct, loop over its fields
-		for i := 0; i < val.NumField(); i++ {
+		// Check if the struct is a time.Time
+		if t, ok := val.Interface().(time.Time); ok {
+			e.addRow(rows, parent, t)
+			return nil
+		}
+
+		if err := e.descend(parent); err != nil {
+			return err
+		}
+		defer func() { e.depth-- }()
+
+		// if the value is a struct, loop over its fields in the order they are written
+		order, err := e.fieldOrder(typ)
+		if err != nil {
+			return err
+		}
+		for _, i := range order {
 			field := typ.Field(i)
-			tag := field.Tag.Get("plain")
-			if tag == "" {
-				tag = field.Tag.Get("form")
-			}
+			tag, opts := parseTag(field)
 
 			if tag == "-" || tag == "" {
 				continue
 			}
 
-			// If no tag, use field name
-			if tag == "" {
-				tag = field.Name
-			}
-
 			// Check if the field is exported
 			if !val.Field(i).CanInterface() {
 				continue
 			}
 
+			// Get name and value
+			fieldName := e.joinKey(parent, tag)
+			start := len(*rows)
 			// Check if the field adhears to the Marshaler interface
 			if m, ok := val.Field(i).Interface().(Marshaler); ok {
-				// If it does then use that to marshal
+				// If it does then use that to marshal, keyed by its tag
 				marshaled, err := m.MarshalPlain()
 				if err != nil {
 					return err
 				}
 
-				sb.WriteString(rowOutput(tag, string(marshaled)))
-				continue
+				e.addRow(rows, tag, string(marshaled))
+			} else if err := e.plainField(rows, fieldName, val.Field(i).Interface()); err != nil {
+				return err
 			}
 
-			// Get name and value
-			fieldName := tag
-			if parent != "" {
-				fieldName = parent + "." + tag
+			// Secret fields hide every row written for them
+			redactor, err := e.redactor(opts)
+			if err != nil {
+				return fmt.Errorf("%s: %w", fieldName, err)
 			}
-			fieldValue := val.Field(i).Interface()
-
-			// If the value is a struct check for time.Time, otherwise recurse
-			if reflect.ValueOf(fieldValue).Kind() == reflect.Struct {
-				// Check if the struct is a time.Time
-				if _, ok := fieldValue.(time.Time); ok {
-					sb.WriteString(rowOutput(fieldName, fieldValue))
-					continue
+			if redactor != nil {
+				for j := start; j < len(*rows); j++ {
+					r := &(*rows)[j]
+					r.value = redactor(r.key, r.value)
+					r.kind = kindValue
 				}
-
-				err := plainStruct(sb, reflect.ValueOf(fieldValue), fieldName)
-				if err != nil {
-					return err
-				}
-				continue
-			} else if reflect.ValueOf(fieldValue).Kind() == reflect.Slice {
-				err := plainStruct(sb, reflect.ValueOf(fieldValue), fieldName)
-				if err != nil {
-					return err
-				}
-				continue
 			}
-
-			sb.WriteString(rowOutput(fieldName, fieldValue))
 		}
 
 		return nil
 	case reflect.Slice:
+		if err := e.descend(parent); err != nil {
+			return err
+		}
+		defer func() { e.depth-- }()
+
 		var sliceValues []string
 		for i := 0; i < val.Len(); i++ {
 			fieldValue := val.Index(i).Interface()
 
 			// Check if the slice element is a struct or another slice, and process it accordingly
-			if reflect.ValueOf(fieldValue).Kind() == reflect.Struct || reflect.ValueOf(fieldValue).Kind() == reflect.Slice {
-				var nestedSB strings.Builder
-				err := plainStruct(&nestedSB, reflect.ValueOf(fieldValue), "")
+			elem := reflect.ValueOf(fieldValue)
+			kind := reflect.Indirect(elem).Kind()
+			if kind == reflect.Struct || kind == reflect.Slice || kind == reflect.Map {
+				var nested []row
+				err := e.plainStruct(&nested, elem, "")
+				var cycle *CycleError
+				if errors.As(err, &cycle) {
+					cycle.Key = elementKey(parent, i, cycle.Key, e.dialect.PathSeparator)
+				}
+				var depth *depthError
+				if errors.As(err, &depth) {
+					depth.key = elementKey(parent, i, depth.key, e.dialect.PathSeparator)
+				}
 				if err != nil {
 					return err
 				}
-				sliceValues = append(sliceValues, nestedSB.String())
+				uncolored := *e
+				uncolored.theme = nil
+				sliceValues = append(sliceValues, uncolored.render(nested))
 			} else {
 				// For simple types, just convert to string and append
+				if elem.Kind() == reflect.Ptr && !elem.IsNil() {
+					fieldValue = elem.Elem().Interface()
+				}
 				sliceValues = append(sliceValues, fmt.Sprintf("%v", fieldValue))
 			}
 		}
 
-		// Join all slice values into a single string with the required format
-		sliceStr := fmt.Sprintf("[%s]", strings.Join(sliceValues, ", "))
-		if parent != "" {
-			parent = parent + ": "
-		}
-		sb.WriteString(parent + sliceStr + "\n")
+		// Join all slice values into a single string with the dialect's list tokens
+		dialect := e.dialect
+		sliceStr := dialect.ListStart + strings.Join(sliceValues, dialect.ListSeparator) + dialect.ListEnd
+		e.addRow(rows, parent, sliceStr)
 
 		return nil
 	}
 
 	// if the value is anything else, print it
-	sb.WriteString(rowOutput(parent, val.Interface()))
+	e.addRow(rows, parent, val.Interface())
+
+	return nil
+}
+
+// plainField adds the rows of a struct field
+func (e *encodeState) plainField(rows *[]row, fieldName string, fieldValue any) error {
+	// If the value is a struct check for time.Time, otherwise recurse
+	switch reflect.ValueOf(fieldValue).Kind() {
+	case reflect.Struct:
+		// Check if the struct is a time.Time
+		if _, ok := fieldValue.(time.Time); ok {
+			e.addRow(rows, fieldName, fieldValue)
+			return nil
+		}
+
+		return e.plainStruct(rows, reflect.ValueOf(fieldValue), fieldName)
+	case reflect.Slice, reflect.Map, reflect.Ptr:
+		return e.plainStruct(rows, reflect.ValueOf(fieldValue), fieldName)
+	}
 
+	e.addRow(rows, fieldName, fieldValue)
 	return nil
 }
 
-func rowOutput(field string, value any) string {
-	if field == "" {
-		return fmt.Sprintf("%v\n", value)
+// joinKey returns the key of a field nested under parent
+func (e *encodeState) joinKey(parent, key string) string {
+	if parent == "" {
+		return key
+	}
+
+	return parent + e.dialect.PathSeparator + key
+}
+
+// addRow adds a key and value to the rows of a record
+func (e *encodeState) addRow(rows *[]row, field string, value any) {
+	*rows = append(*rows, row{key: field, value: fmt.Sprintf("%v", value), kind: kindOf(value)})
+}
+
+// render writes the rows of a record, one per line
+func (e *encodeState) render(rows []row) string {
+	keys := make([]string, len(rows))
+	width := 0
+	for i, r := range rows {
+		keys[i] = e.formatKey(r.key)
+		if e.align {
+			width = max(width, utf8.RuneCountInString(keys[i]))
+		}
+	}
+
+	var sb strings.Builder
+	for i, r := range rows {
+		value := e.formatValue(r.value)
+		if keys[i] == "" {
+			sb.WriteString(e.colorValue(e.escapeValue("", value), r.kind) + "\n")
+			continue
+		}
+
+		key := keys[i]
+		if e.theme != nil {
+			key = colorize(e.theme.Key, key)
+		}
+
+		prefix := keys[i] + e.dialect.Separator
+		if e.align {
+			prefix += strings.Repeat(" ", width-utf8.RuneCountInString(keys[i]))
+		}
+		indent := utf8.RuneCountInString(prefix)
+		value = e.escapeValue(prefix, e.wrapValue(value, indent))
+		sb.WriteString(key + prefix[len(keys[i]):] + e.colorValue(value, r.kind) + "\n")
+	}
+
+	return sb.String()
+}
+
+// escapeValue escapes the backslashes ending a value written after prefix,
+// so the line is not read as continued
+func (e *encodeState) escapeValue(prefix, value string) string {
+	if e.dialect.Syntax != SyntaxPlain {
+		return value
+	}
+
+	return escapeContinued(prefix + value)[len(prefix):]
+}
+
+// colorValue colors a value with the theme, when there is one
+func (e *encodeState) colorValue(value string, kind valueKind) string {
+	if e.theme == nil {
+		return value
+	}
+
+	return colorize(e.theme.color(kind), value)
+}
+
+// formatKey escapes a key for the syntax of the dialect
+func (e *encodeState) formatKey(key string) string {
+	if e.dialect.Syntax == SyntaxProperties {
+		key = escapeProperty(key, true)
+	}
+	if e.dialect.UpperCaseKeys {
+		key = strings.ToUpper(key)
+	}
+
+	return key
+}
+
+// formatValue escapes or quotes a value for the syntax of the dialect
+func (e *encodeState) formatValue(value string) string {
+	switch e.dialect.Syntax {
+	case SyntaxProperties:
+		return escapeProperty(value, false)
+	case SyntaxDotenv:
+		return quoteDotenv(value)
 	}
 
-	return fmt.Sprintf("%s: %v\n", field, value)
+	return value
 }
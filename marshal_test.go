@@ -309,3 +309,83 @@ func TestPlain_Marshaler(t *testing.T) {
 		t.Fatalf("Marshaler was expecting %s got %s", expected, string(resp))
 	}
 }
+
+func TestPlain_MarshalMap(t *testing.T) {
+	t.Run("Map", func(t *testing.T) {
+		resp, err := Marshal(map[string]any{
+			"name": "test",
+			"age":  35,
+			"sub":  map[string]any{"name": "test2", "tags": []any{"a", "b"}},
+		})
+		if err != nil {
+			t.Fatalf("Was not expecting an error got %s", err)
+		}
+
+		expected := "age: 35\nname: test\nsub.name: test2\nsub.tags: [a, b]"
+		if string(resp) != expected {
+			t.Fatalf("Map was expecting %s\n got %s", expected, string(resp))
+		}
+	})
+
+	t.Run("Map in Struct", func(t *testing.T) {
+		type TestMapStruct struct {
+			Name   string            `form:"name"`
+			Labels map[string]string `form:"labels"`
+		}
+
+		resp, err := Marshal(TestMapStruct{Name: "test", Labels: map[string]string{"env": "prod", "app": "api"}})
+		if err != nil {
+			t.Fatalf("Was not expecting an error got %s", err)
+		}
+
+		expected := "name: test\nlabels.app: api\nlabels.env: prod"
+		if string(resp) != expected {
+			t.Fatalf("Map in Struct was expecting %s\n got %s", expected, string(resp))
+		}
+	})
+
+	t.Run("Slice of Maps", func(t *testing.T) {
+		resp, err := Marshal([]any{map[string]any{"name": "test1"}, map[string]any{"name": "test2", "empty": nil}})
+		if err != nil {
+			t.Fatalf("Was not expecting an error got %s", err)
+		}
+
+		expected := "name: test1\n\nempty: <nil>\nname: test2"
+		if string(resp) != expected {
+			t.Fatalf("Slice of Maps was expecting %s\n got %s", expected, string(resp))
+		}
+	})
+}
+
+func TestPlain_MarshalTagOptions(t *testing.T) {
+	type TestTagOptions struct {
+		Port int      `plain:"port,default=8080"`
+		Tags []string `plain:"tags,default=[a, b]"`
+	}
+
+	resp, err := Marshal(TestTagOptions{Port: 1, Tags: []string{"c"}})
+	if err != nil {
+		t.Fatalf("Was not expecting an error got %s", err)
+	}
+
+	expected := "port: 1\ntags: [c]"
+	if string(resp) != expected {
+		t.Fatalf("Tag options was expecting %s\n got %s", expected, string(resp))
+	}
+}
+
+func TestPlain_MarshalNil(t *testing.T) {
+	var nilPointer *TestSingleField
+
+	for name, value := range map[string]any{"Nil": nil, "Nil pointer": nilPointer} {
+		t.Run(name, func(t *testing.T) {
+			resp, err := Marshal(value)
+			if err != nil {
+				t.Fatalf("Was not expecting an error got %s", err)
+			}
+			if len(resp) != 0 {
+				t.Fatalf("%s was expecting no output got %q", name, string(resp))
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package plain
+
+import (
+	"errors"
+	"reflect"
+)
+
+// mergeSlice empties a slice field before a new value is added, following
+// its merge tag option. Slices tagged merge=append keep their elements.
+func mergeSlice(field reflect.Value, opts tagOptions, replace bool) error {
+	switch opts["merge"] {
+	case "", "replace":
+		if replace && field.CanSet() {
+			field.Set(reflect.Zero(field.Type()))
+		}
+	case "append":
+	default:
+		return errors.New("invalid merge option: " + opts["merge"])
+	}
+
+	return nil
+}
+
+// Merge decodes each document in turn into the struct or map pointed to by
+// dst, for layered configuration. Later documents override the values of
+// earlier ones and of dst, and replace the contents of slices unless they
+// are tagged merge=append. Defaults and validation are applied once, after
+// the last document, so a required key may come from any of them.
+func Merge(dst any, docs ...[]byte) error {
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return errors.New("dst must be a non-nil pointer")
+	}
+
+	rv = rv.Elem()
+	d := newDecodeState(decodeOptions{})
+	switch rv.Kind() {
+	case reflect.Struct:
+		seen := map[string]bool{}
+		for _, doc := range docs {
+			if err := d.mergeStruct(doc, rv, 1, seen); err != nil {
+				return err
+			}
+		}
+
+		if err := d.finishStruct(rv, seen); err != nil {
+			return err
+		}
+	case reflect.Map:
+		for _, doc := range docs {
+			if err := d.unmarshalMap(doc, rv, 1); err != nil {
+				return err
+			}
+		}
+	default:
+		return errors.New("dst must point to a struct or a map")
+	}
+
+	return d.finish()
+}
@@ -0,0 +1,119 @@
+package plain
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type TestMerge struct {
+	Name    string   `plain:"name,required"`
+	Hosts   []string `plain:"hosts"`
+	Plugins []string `plain:"plugins,merge=append"`
+	Port    int      `plain:"port,default=80"`
+}
+
+func TestMergeSlices(t *testing.T) {
+	t.Run("Replace by default", func(t *testing.T) {
+		result := TestMerge{Name: "app", Hosts: []string{"a"}, Plugins: []string{"x"}}
+		if err := Unmarshal([]byte("hosts: [b]\nplugins: [y]"), &result); err != nil {
+			t.Fatalf("Was not expecting an error got %s", err)
+		}
+
+		if !reflect.DeepEqual(result.Hosts, []string{"b"}) {
+			t.Fatalf("Unmarshal was expecting hosts replaced got %v", result.Hosts)
+		}
+		if !reflect.DeepEqual(result.Plugins, []string{"x", "y"}) {
+			t.Fatalf("Unmarshal was expecting plugins appended got %v", result.Plugins)
+		}
+	})
+
+	t.Run("Repeated keys", func(t *testing.T) {
+		result := TestMerge{Name: "app", Hosts: []string{"a"}}
+		if err := Unmarshal([]byte("hosts: b\nhosts: c"), &result); err != nil {
+			t.Fatalf("Was not expecting an error got %s", err)
+		}
+		if !reflect.DeepEqual(result.Hosts, []string{"b", "c"}) {
+			t.Fatalf("Unmarshal was expecting [b c] got %v", result.Hosts)
+		}
+	})
+
+	t.Run("Records", func(t *testing.T) {
+		result := []TestSingleField{{Name: "old"}}
+		if err := Unmarshal([]byte("name: new"), &result); err != nil {
+			t.Fatalf("Was not expecting an error got %s", err)
+		}
+		if !reflect.DeepEqual(result, []TestSingleField{{Name: "new"}}) {
+			t.Fatalf("Unmarshal was expecting the records replaced got %v", result)
+		}
+	})
+
+	t.Run("Invalid option", func(t *testing.T) {
+		var result struct {
+			Hosts []string `plain:"hosts,merge=mix"`
+		}
+		if err := Unmarshal([]byte("hosts: [a]"), &result); err == nil {
+			t.Fatal("Was expecting an error for an invalid merge option")
+		}
+	})
+}
+
+type TestSingleField struct {
+	Name string `plain:"name"`
+}
+
+func TestMergeDocs(t *testing.T) {
+	base := []byte("hosts: [a, b]\nplugins: [x]\nport: 8080")
+	local := []byte("name: app\nhosts: [c]\nplugins: [y]")
+
+	t.Run("Struct", func(t *testing.T) {
+		var result TestMerge
+		if err := Merge(&result, base, local); err != nil {
+			t.Fatalf("Was not expecting an error got %s", err)
+		}
+
+		expected := TestMerge{Name: "app", Hosts: []string{"c"}, Plugins: []string{"x", "y"}, Port: 8080}
+		if !reflect.DeepEqual(result, expected) {
+			t.Fatalf("Merge was expecting %+v\n got %+v", expected, result)
+		}
+	})
+
+	t.Run("Validation after the last document", func(t *testing.T) {
+		var result TestMerge
+		err := Merge(&result, base)
+
+		var validationErrs ValidationErrors
+		if !errors.As(err, &validationErrs) || len(validationErrs) != 1 || validationErrs[0].Key != "name" {
+			t.Fatalf("Was expecting a required error for name got %v", err)
+		}
+	})
+
+	t.Run("Defaults", func(t *testing.T) {
+		var result TestMerge
+		if err := Merge(&result, local); err != nil {
+			t.Fatalf("Was not expecting an error got %s", err)
+		}
+		if result.Port != 80 {
+			t.Fatalf("Merge was expecting the default port got %d", result.Port)
+		}
+	})
+
+	t.Run("Map", func(t *testing.T) {
+		result := map[string]any{}
+		if err := Merge(&result, []byte("a: 1\nb.c: 2"), []byte("a: 3\nb.d: 4")); err != nil {
+			t.Fatalf("Was not expecting an error got %s", err)
+		}
+
+		expected := map[string]any{"a": "3", "b": map[string]any{"c": "2", "d": "4"}}
+		if !reflect.DeepEqual(result, expected) {
+			t.Fatalf("Merge was expecting %v\n got %v", expected, result)
+		}
+	})
+
+	t.Run("Invalid target", func(t *testing.T) {
+		var result []TestMerge
+		if err := Merge(&result, base); err == nil {
+			t.Fatal("Was expecting an error for a slice target")
+		}
+	})
+}
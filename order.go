@@ -0,0 +1,55 @@
+package plain
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// fieldOrder returns the indexes of the fields of a struct type in the order
+// they are written. Fields tagged with order=N come first, by ascending N,
+// and the others follow in declaration order, or by tag name when keys are
+// sorted.
+func (e *encodeState) fieldOrder(typ reflect.Type) ([]int, error) {
+	type position struct {
+		index   int
+		name    string
+		order   int
+		ordered bool
+	}
+
+	positions := make([]position, 0, typ.NumField())
+	for i := 0; i < typ.NumField(); i++ {
+		tag, opts := parseTag(typ.Field(i))
+		p := position{index: i, name: tag}
+		if value, ok := opts["order"]; ok {
+			order, err := strconv.Atoi(value)
+			if err != nil {
+				return nil, errors.New("invalid order for " + tag + ": " + value)
+			}
+			p.order, p.ordered = order, true
+		}
+		positions = append(positions, p)
+	}
+
+	sort.SliceStable(positions, func(i, j int) bool {
+		a, b := positions[i], positions[j]
+		switch {
+		case a.ordered != b.ordered:
+			return a.ordered
+		case a.ordered:
+			return a.order < b.order
+		case e.sortKeys:
+			return a.name < b.name
+		}
+		return false
+	})
+
+	indexes := make([]int, len(positions))
+	for i, p := range positions {
+		indexes[i] = p.index
+	}
+
+	return indexes, nil
+}
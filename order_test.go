@@ -0,0 +1,86 @@
+package plain
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+type TestOrderServer struct {
+	Port int    `plain:"port"`
+	Host string `plain:"host,order=1"`
+}
+
+type TestOrder struct {
+	Zone    string            `plain:"zone"`
+	Server  TestOrderServer   `plain:"server"`
+	Labels  map[string]string `plain:"labels"`
+	Name    string            `plain:"name,order=1"`
+	Version int               `plain:"version,order=0"`
+	Active  bool              `plain:"active"`
+}
+
+func TestKeyOrder(t *testing.T) {
+	value := TestOrder{
+		Zone:    "eu",
+		Server:  TestOrderServer{Port: 80, Host: "localhost"},
+		Labels:  map[string]string{"tier": "web", "env": "prod"},
+		Name:    "api",
+		Version: 2,
+		Active:  true,
+	}
+
+	t.Run("Order tag", func(t *testing.T) {
+		resp, err := Marshal(value)
+		if err != nil {
+			t.Fatalf("Was not expecting an error got %s", err)
+		}
+
+		expected := strings.Join([]string{
+			"version: 2",
+			"name: api",
+			"zone: eu",
+			"server.host: localhost",
+			"server.port: 80",
+			"labels.env: prod",
+			"labels.tier: web",
+			"active: true",
+		}, "\n")
+		if string(resp) != expected {
+			t.Fatalf("Marshal was expecting %q\n got %q", expected, string(resp))
+		}
+	})
+
+	t.Run("Sorted keys", func(t *testing.T) {
+		var buf bytes.Buffer
+		enc := NewEncoder(&buf)
+		enc.SetSortKeys(true)
+		if err := enc.Encode(value); err != nil {
+			t.Fatalf("Was not expecting an error got %s", err)
+		}
+
+		expected := strings.Join([]string{
+			"version: 2",
+			"name: api",
+			"active: true",
+			"labels.env: prod",
+			"labels.tier: web",
+			"server.host: localhost",
+			"server.port: 80",
+			"zone: eu",
+		}, "\n") + "\n"
+		if buf.String() != expected {
+			t.Fatalf("Encode was expecting %q\n got %q", expected, buf.String())
+		}
+	})
+
+	t.Run("Invalid order", func(t *testing.T) {
+		type invalid struct {
+			Name string `plain:"name,order=first"`
+		}
+
+		if _, err := Marshal(invalid{}); err == nil || !strings.Contains(err.Error(), "invalid order for name") {
+			t.Fatalf("Marshal was expecting an invalid order error got %v", err)
+		}
+	})
+}
@@ -0,0 +1,388 @@
+package plain
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// A SyntaxError describes a line that Parse cannot read, such as a line
+// with a separator but no key.
+type SyntaxError struct {
+	Line int    // 1-based line number
+	Msg  string // description of the problem
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Msg)
+}
+
+// NodeKind is the kind of a Node.
+type NodeKind int
+
+const (
+	KeyValueNode NodeKind = iota // a key: value line
+	CommentNode                  // a line starting with #
+	BlankNode                    // an empty or whitespace only line
+	ValueNode                    // a line without a key, such as an element of a slice of basic values
+)
+
+// A Node is a single line of a Document. Positions refer to the parsed
+// source and are not updated when the document is edited.
+type Node struct {
+	Kind  NodeKind
+	Key   string // key of a KeyValueNode
+	Value string // value of a KeyValueNode, or the text of a ValueNode
+	Text  string // text after the # of a CommentNode
+
+	Line   int // 1-based line number
+	Column int // 1-based byte column of the key or comment marker
+	Offset int // byte offset of the start of the line
+
+	raw                    []byte // source line including its line ending
+	origKey, origValue     string
+	origText               string
+	indent, sep, lineEnder string
+}
+
+// Raw returns the text of the node as Bytes writes it, including the line
+// ending. Nodes that were not changed return their source bytes.
+func (n *Node) Raw() []byte {
+	if n.raw != nil && n.Key == n.origKey && n.Value == n.origValue && n.Text == n.origText {
+		return n.raw
+	}
+
+	var line string
+	switch n.Kind {
+	case KeyValueNode:
+		sep := n.sep
+		if sep == "" {
+			sep = ": "
+		}
+		if n.Value == "" {
+			sep = strings.TrimRight(sep, " \t")
+		} else if !strings.HasSuffix(sep, " ") && !strings.HasSuffix(sep, "\t") {
+			sep += " "
+		}
+		line = escapeContinued(n.indent + n.Key + sep + n.Value)
+	case CommentNode:
+		line = n.indent + "#" + n.Text
+	case ValueNode:
+		line = escapeContinued(n.indent + n.Value)
+	}
+
+	return []byte(line + n.lineEnder)
+}
+
+// terminate adds a line ending to the last line of a document before more
+// lines are added after it
+func (n *Node) terminate() {
+	if n.lineEnder != "" {
+		return
+	}
+
+	n.lineEnder = "\n"
+	if n.raw != nil {
+		n.raw = append(n.raw[:len(n.raw):len(n.raw)], '\n')
+	}
+}
+
+// A Record is a run of lines holding at least one key, separated from other
+// records by blank lines.
+type Record struct {
+	doc   *Document
+	nodes []*Node
+}
+
+// Nodes returns the key/value, value and comment nodes of the record in
+// order.
+func (r *Record) Nodes() []*Node {
+	return r.nodes
+}
+
+// Get returns the value of key, the full dotted path such as sub.name. If
+// the key is repeated the last value is returned, the same one Unmarshal
+// keeps.
+func (r *Record) Get(key string) (string, bool) {
+	if n := r.find(key); n != nil {
+		return n.Value, true
+	}
+
+	return "", false
+}
+
+// Set changes the value of key, adding it after the last line of the record
+// if it does not exist.
+func (r *Record) Set(key, value string) {
+	if n := r.find(key); n != nil {
+		n.Value = value
+		return
+	}
+
+	n := &Node{Kind: KeyValueNode, Key: key, Value: value, lineEnder: "\n"}
+	if len(r.nodes) > 0 {
+		last := r.nodes[len(r.nodes)-1]
+		n.indent = last.indent
+		r.doc.insertAfter(last, n)
+	} else {
+		r.doc.insertAfter(nil, n)
+	}
+	r.nodes = append(r.nodes, n)
+}
+
+// Delete removes every line with key and reports whether there was one.
+func (r *Record) Delete(key string) bool {
+	deleted := false
+	kept := r.nodes[:0]
+	for _, n := range r.nodes {
+		if n.Kind == KeyValueNode && n.Key == key {
+			r.doc.remove(n)
+			deleted = true
+			continue
+		}
+		kept = append(kept, n)
+	}
+	r.nodes = kept
+
+	return deleted
+}
+
+func (r *Record) find(key string) *Node {
+	for i := len(r.nodes) - 1; i >= 0; i-- {
+		if r.nodes[i].Kind == KeyValueNode && r.nodes[i].Key == key {
+			return r.nodes[i]
+		}
+	}
+
+	return nil
+}
+
+// A Document is the syntax tree of a plain document. It keeps the source of
+// every line, so Bytes returns untouched content byte for byte.
+type Document struct {
+	nodes   []*Node
+	records []*Record
+}
+
+// Parse parses data into a Document. Lines without a key are kept as value
+// nodes, and a line with a separator but no key returns a *SyntaxError.
+func Parse(data []byte) (*Document, error) {
+	doc := &Document{}
+
+	var current *Record
+	offset := 0
+	for line := 1; offset < len(data); line++ {
+		end := bytes.IndexByte(data[offset:], '\n')
+		if end < 0 {
+			end = len(data)
+		} else {
+			end += offset + 1
+		}
+
+		// A continued value takes the following lines into the node
+		first := line
+		for end < len(data) && !isComment(strings.TrimSpace(string(data[offset:end]))) && isContinued(strings.TrimSuffix(string(data[offset:end]), "\n")) {
+			next := bytes.IndexByte(data[end:], '\n')
+			if next < 0 {
+				end = len(data)
+			} else {
+				end += next + 1
+			}
+			line++
+		}
+
+		n, err := parseNode(data[offset:end], first, offset)
+		if err != nil {
+			return nil, err
+		}
+		doc.nodes = append(doc.nodes, n)
+		offset = end
+
+		// Blank lines end the current block
+		if n.Kind == BlankNode {
+			current = nil
+			continue
+		}
+
+		if current == nil {
+			current = &Record{doc: doc}
+		}
+		current.nodes = append(current.nodes, n)
+
+		// Blocks without a key are not records
+		if n.Kind == KeyValueNode && (len(doc.records) == 0 || doc.records[len(doc.records)-1] != current) {
+			doc.records = append(doc.records, current)
+		}
+	}
+
+	return doc, nil
+}
+
+func parseNode(raw []byte, line, offset int) (*Node, error) {
+	content := string(raw)
+	lineEnder := ""
+	for _, ending := range []string{"\r\n", "\n"} {
+		if strings.HasSuffix(content, ending) {
+			content = strings.TrimSuffix(content, ending)
+			lineEnder = ending
+			break
+		}
+	}
+
+	n := &Node{Line: line, Offset: offset, raw: raw, lineEnder: lineEnder}
+
+	trimmed := strings.TrimSpace(content)
+	if trimmed == "" {
+		n.Kind = BlankNode
+		return n, nil
+	}
+
+	n.indent = content[:len(content)-len(strings.TrimLeft(content, " \t"))]
+	n.Column = len(n.indent) + 1
+
+	if isComment(trimmed) {
+		n.Kind = CommentNode
+		n.Text = strings.TrimPrefix(trimmed, "#")
+		n.origText = n.Text
+		return n, nil
+	}
+
+	// Join continued lines into one and unescape the backslashes ending it
+	var lines []sourceLine
+	for _, l := range strings.Split(content, "\n") {
+		lines = append(lines, sourceLine{text: l})
+	}
+	content = continuedLines(lines)[0].text
+
+	// Lines without a key are kept as they are, Unmarshal reads them as the
+	// elements of a slice of basic values and skips them in records
+	key, value, found := strings.Cut(content[len(n.indent):], ":")
+	if !found {
+		n.Kind = ValueNode
+		n.Value = strings.TrimSpace(content)
+		n.origValue = n.Value
+		return n, nil
+	}
+	if strings.TrimSpace(key) == "" {
+		return nil, &SyntaxError{Line: line, Msg: "expected key: value"}
+	}
+
+	n.Kind = KeyValueNode
+	n.Key = strings.TrimSpace(key)
+	n.Value = strings.TrimSpace(value)
+	n.origKey, n.origValue = n.Key, n.Value
+	n.sep = key[len(strings.TrimRight(key, " \t")):] + ":" + value[:len(value)-len(strings.TrimLeft(value, " \t"))]
+
+	return n, nil
+}
+
+// Nodes returns every line of the document in order, including blank lines
+// and comments outside of records.
+func (d *Document) Nodes() []*Node {
+	return d.nodes
+}
+
+// Records returns the records of the document in order.
+func (d *Document) Records() []*Record {
+	return d.records
+}
+
+// Get returns the value of key in the first record.
+func (d *Document) Get(key string) (string, bool) {
+	if len(d.records) == 0 {
+		return "", false
+	}
+
+	return d.records[0].Get(key)
+}
+
+// Set changes the value of key in the first record, creating the record if
+// the document has none.
+func (d *Document) Set(key, value string) {
+	if len(d.records) == 0 {
+		d.records = append(d.records, &Record{doc: d})
+	}
+
+	d.records[0].Set(key, value)
+}
+
+// Delete removes key from the first record and reports whether it was there.
+func (d *Document) Delete(key string) bool {
+	if len(d.records) == 0 {
+		return false
+	}
+
+	return d.records[0].Delete(key)
+}
+
+// Bytes returns the text of the document.
+func (d *Document) Bytes() []byte {
+	var buf bytes.Buffer
+	for _, n := range d.nodes {
+		buf.Write(n.Raw())
+	}
+
+	return buf.Bytes()
+}
+
+// blocks returns the runs of non blank nodes, including comment only ones
+func (d *Document) blocks() [][]*Node {
+	var blocks [][]*Node
+	var current []*Node
+	for _, n := range d.nodes {
+		if n.Kind == BlankNode {
+			if current != nil {
+				blocks = append(blocks, current)
+			}
+			current = nil
+			continue
+		}
+		current = append(current, n)
+	}
+	if current != nil {
+		blocks = append(blocks, current)
+	}
+
+	return blocks
+}
+
+// insertAfter adds n after the node prev, or at the end of the document as
+// a new block when prev is nil
+func (d *Document) insertAfter(prev *Node, n *Node) {
+	if prev == nil {
+		if len(d.nodes) > 0 {
+			d.nodes[len(d.nodes)-1].terminate()
+		}
+		if len(d.nodes) > 0 && d.nodes[len(d.nodes)-1].Kind != BlankNode {
+			d.nodes = append(d.nodes, &Node{Kind: BlankNode, lineEnder: "\n"})
+		}
+		d.nodes = append(d.nodes, n)
+		return
+	}
+
+	for i, existing := range d.nodes {
+		if existing != prev {
+			continue
+		}
+
+		prev.terminate()
+		n.lineEnder = prev.lineEnder
+
+		d.nodes = append(d.nodes[:i+1], append([]*Node{n}, d.nodes[i+1:]...)...)
+		return
+	}
+}
+
+func (d *Document) remove(n *Node) {
+	for i, existing := range d.nodes {
+		if existing == n {
+			d.nodes = append(d.nodes[:i], d.nodes[i+1:]...)
+			return
+		}
+	}
+}
+
+// isComment reports whether a trimmed line is a comment
+func isComment(line string) bool {
+	return strings.HasPrefix(line, "#")
+}
@@ -0,0 +1,169 @@
+package plain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	t.Run("Round trip", func(t *testing.T) {
+		inputs := []string{
+			"",
+			"name: John Doe",
+			"# header\r\n\r\nname :John Doe  \r\n  age:42\r\n\r\n\r\nname: Jane Doe\r\n",
+			"\n\n  # only a comment\n\t\nsub.name:   Jane Doe\nempty:\n",
+		}
+
+		for _, input := range inputs {
+			doc, err := Parse([]byte(input))
+			if err != nil {
+				t.Fatalf("Was not expecting an error got %s", err)
+			}
+			if string(doc.Bytes()) != input {
+				t.Fatalf("Parse round trip was expecting %q\n got %q", input, string(doc.Bytes()))
+			}
+		}
+	})
+
+	t.Run("Nodes", func(t *testing.T) {
+		doc, err := Parse([]byte("# people\n\nname: John Doe\n  # age\n  age: 42\n\nname: Jane Doe"))
+		if err != nil {
+			t.Fatalf("Was not expecting an error got %s", err)
+		}
+
+		if len(doc.Nodes()) != 7 {
+			t.Fatalf("Was expecting 7 nodes got %d", len(doc.Nodes()))
+		}
+
+		records := doc.Records()
+		if len(records) != 2 {
+			t.Fatalf("Was expecting 2 records got %d", len(records))
+		}
+
+		nodes := records[0].Nodes()
+		if len(nodes) != 3 {
+			t.Fatalf("Was expecting 3 nodes in the first record got %d", len(nodes))
+		}
+
+		comment := nodes[1]
+		if comment.Kind != CommentNode || comment.Text != " age" || comment.Line != 4 || comment.Column != 3 {
+			t.Fatalf("Unexpected comment node %+v", comment)
+		}
+
+		age := nodes[2]
+		if age.Kind != KeyValueNode || age.Key != "age" || age.Value != "42" || age.Line != 5 || age.Column != 3 || age.Offset != 33 {
+			t.Fatalf("Unexpected key/value node %+v", age)
+		}
+		if string(age.Raw()) != "  age: 42\n" {
+			t.Fatalf("Unexpected raw text %q", string(age.Raw()))
+		}
+
+		if value, ok := records[1].Get("name"); !ok || value != "Jane Doe" {
+			t.Fatalf("Was expecting Jane Doe got %q", value)
+		}
+	})
+
+	t.Run("Values without keys", func(t *testing.T) {
+		data := []byte("1\n\n  2\nname: John Doe\n")
+		doc, err := Parse(data)
+		if err != nil {
+			t.Fatalf("Was not expecting an error got %s", err)
+		}
+
+		nodes := doc.Nodes()
+		if nodes[0].Kind != ValueNode || nodes[0].Value != "1" || nodes[2].Kind != ValueNode || nodes[2].Value != "2" {
+			t.Fatalf("Was expecting value nodes got %+v and %+v", nodes[0], nodes[2])
+		}
+		if len(doc.Records()) != 1 {
+			t.Fatalf("Was expecting 1 record got %d", len(doc.Records()))
+		}
+		if string(doc.Bytes()) != string(data) {
+			t.Fatalf("Bytes was expecting %q got %q", data, doc.Bytes())
+		}
+	})
+
+	t.Run("Syntax error", func(t *testing.T) {
+		_, err := Parse([]byte("name: John Doe\n\n: John Doe"))
+
+		var syntaxErr *SyntaxError
+		if !errors.As(err, &syntaxErr) || syntaxErr.Line != 3 {
+			t.Fatalf("Was expecting a SyntaxError on line 3 got %v", err)
+		}
+	})
+}
+
+func TestDocumentEdit(t *testing.T) {
+	t.Run("Get", func(t *testing.T) {
+		doc, _ := Parse([]byte("name: John Doe\nsub.name: Jane Doe\nname: Johnny"))
+
+		if value, ok := doc.Get("name"); !ok || value != "Johnny" {
+			t.Fatalf("Get was expecting the last value got %q", value)
+		}
+		if value, ok := doc.Get("sub.name"); !ok || value != "Jane Doe" {
+			t.Fatalf("Get was expecting Jane Doe got %q", value)
+		}
+		if _, ok := doc.Get("missing"); ok {
+			t.Fatal("Get was not expecting to find a missing key")
+		}
+	})
+
+	t.Run("Set existing", func(t *testing.T) {
+		doc, _ := Parse([]byte("# config\nname :  John Doe\r\nport:8080\r\nempty:\n\nname: Jane Doe"))
+		doc.Set("name", "Johnny")
+		doc.Set("port", "9090")
+		doc.Set("empty", "value")
+
+		expected := "# config\nname :  Johnny\r\nport: 9090\r\nempty: value\n\nname: Jane Doe"
+		if string(doc.Bytes()) != expected {
+			t.Fatalf("Set was expecting %q\n got %q", expected, string(doc.Bytes()))
+		}
+	})
+
+	t.Run("Set new", func(t *testing.T) {
+		doc, _ := Parse([]byte("name: John Doe\n\nname: Jane Doe"))
+		doc.Set("age", "42")
+		doc.Records()[1].Set("age", "36")
+
+		expected := "name: John Doe\nage: 42\n\nname: Jane Doe\nage: 36\n"
+		if string(doc.Bytes()) != expected {
+			t.Fatalf("Set was expecting %q\n got %q", expected, string(doc.Bytes()))
+		}
+	})
+
+	t.Run("Set empty document", func(t *testing.T) {
+		doc, _ := Parse([]byte("# header"))
+		doc.Set("name", "John Doe")
+
+		expected := "# header\n\nname: John Doe\n"
+		if string(doc.Bytes()) != expected {
+			t.Fatalf("Set was expecting %q\n got %q", expected, string(doc.Bytes()))
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		doc, _ := Parse([]byte("name: John Doe\nage: 42\nname: Johnny\n"))
+		if !doc.Delete("name") {
+			t.Fatal("Delete was expecting to find name")
+		}
+		if doc.Delete("missing") {
+			t.Fatal("Delete was not expecting to find missing")
+		}
+
+		expected := "age: 42\n"
+		if string(doc.Bytes()) != expected {
+			t.Fatalf("Delete was expecting %q\n got %q", expected, string(doc.Bytes()))
+		}
+	})
+
+	t.Run("Edit node", func(t *testing.T) {
+		doc, _ := Parse([]byte("# old\nname: John Doe"))
+		nodes := doc.Nodes()
+		nodes[0].Text = " new"
+		nodes[1].Key = "full_name"
+
+		expected := "# new\nfull_name: John Doe"
+		if string(doc.Bytes()) != expected {
+			t.Fatalf("Edit was expecting %q\n got %q", expected, string(doc.Bytes()))
+		}
+	})
+}
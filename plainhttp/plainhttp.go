@@ -0,0 +1,111 @@
+// Package plainhttp binds text/plain request bodies and renders text/plain
+// responses using the plain format.
+package plainhttp
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/brianvoe/plain"
+)
+
+// ContentType is the Content-Type header value set by Render.
+const ContentType = "text/plain; charset=utf-8"
+
+// DefaultMaxBytes is the request body limit used by Bind.
+const DefaultMaxBytes = 1 << 20
+
+var (
+	// ErrUnsupportedMediaType is returned when a request body is not text/plain.
+	ErrUnsupportedMediaType = errors.New("request content type must be text/plain")
+
+	// ErrBodyTooLarge is returned when a request body exceeds the bind limit.
+	ErrBodyTooLarge = errors.New("request body too large")
+)
+
+// Bind decodes a text/plain request body into v, reading at most
+// DefaultMaxBytes. A missing Content-Type header is treated as text/plain.
+func Bind(r *http.Request, v any) error {
+	return BindLimit(r, v, DefaultMaxBytes)
+}
+
+// BindLimit is like Bind but reads at most limit bytes of the body.
+func BindLimit(r *http.Request, v any, limit int64) error {
+	if err := checkContentType(r.Header.Get("Content-Type")); err != nil {
+		return err
+	}
+	if r.Body == nil {
+		return plain.Unmarshal(nil, v)
+	}
+
+	err := plain.NewDecoder(http.MaxBytesReader(nil, r.Body, limit)).Decode(v)
+	var maxErr *http.MaxBytesError
+	if errors.As(err, &maxErr) {
+		return fmt.Errorf("%w: limit is %d bytes", ErrBodyTooLarge, maxErr.Limit)
+	}
+
+	return err
+}
+
+func checkContentType(contentType string) error {
+	if contentType == "" {
+		return nil
+	}
+
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil || mediaType != "text/plain" {
+		return ErrUnsupportedMediaType
+	}
+
+	// Only utf-8 and its ascii subset can be decoded as is
+	if charset, ok := params["charset"]; ok {
+		switch strings.ToLower(charset) {
+		case "utf-8", "utf8", "us-ascii":
+		default:
+			return fmt.Errorf("%w: unsupported charset %s", ErrUnsupportedMediaType, charset)
+		}
+	}
+
+	return nil
+}
+
+// Render writes v to w as a text/plain response with the given status.
+// If v cannot be encoded nothing is written, so the caller is still free to
+// send an error response.
+func Render(w http.ResponseWriter, status int, v any) error {
+	rw := &responseWriter{w: w, status: status}
+	if err := plain.NewEncoder(rw).Encode(v); err != nil {
+		return err
+	}
+
+	// Empty output never reaches Write, so make sure the header goes out
+	rw.writeHeader()
+
+	return nil
+}
+
+// responseWriter delays sending the header until the first write so an
+// encoding error leaves the response untouched.
+type responseWriter struct {
+	w           http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (rw *responseWriter) writeHeader() {
+	if rw.wroteHeader {
+		return
+	}
+
+	rw.w.Header().Set("Content-Type", ContentType)
+	rw.w.WriteHeader(rw.status)
+	rw.wroteHeader = true
+}
+
+func (rw *responseWriter) Write(p []byte) (int, error) {
+	rw.writeHeader()
+	return rw.w.Write(p)
+}
@@ -0,0 +1,109 @@
+package plainhttp
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type testEmployee struct {
+	Name string `plain:"name"`
+	Age  int    `plain:"age"`
+}
+
+type testMarshalerError struct{}
+
+func (testMarshalerError) MarshalPlain() ([]byte, error) {
+	return nil, errors.New("marshal error")
+}
+
+func TestBind(t *testing.T) {
+	t.Run("Text plain", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("name: John Doe\nage: 42"))
+		req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+
+		var emp testEmployee
+		if err := Bind(req, &emp); err != nil {
+			t.Fatalf("Was not expecting an error got %s", err)
+		}
+		if emp != (testEmployee{Name: "John Doe", Age: 42}) {
+			t.Fatalf("Bind got %+v", emp)
+		}
+	})
+
+	t.Run("No content type", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("name: John Doe"))
+
+		var emp testEmployee
+		if err := Bind(req, &emp); err != nil {
+			t.Fatalf("Was not expecting an error got %s", err)
+		}
+		if emp.Name != "John Doe" {
+			t.Fatalf("Bind got %+v", emp)
+		}
+	})
+
+	t.Run("Wrong content type", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"John Doe"}`))
+		req.Header.Set("Content-Type", "application/json")
+
+		var emp testEmployee
+		if err := Bind(req, &emp); !errors.Is(err, ErrUnsupportedMediaType) {
+			t.Fatalf("Was expecting ErrUnsupportedMediaType got %v", err)
+		}
+	})
+
+	t.Run("Wrong charset", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("name: John Doe"))
+		req.Header.Set("Content-Type", "text/plain; charset=utf-16")
+
+		var emp testEmployee
+		if err := Bind(req, &emp); !errors.Is(err, ErrUnsupportedMediaType) {
+			t.Fatalf("Was expecting ErrUnsupportedMediaType got %v", err)
+		}
+	})
+
+	t.Run("Too large", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("name: John Doe\nage: 42"))
+		req.Header.Set("Content-Type", "text/plain")
+
+		var emp testEmployee
+		if err := BindLimit(req, &emp, 10); !errors.Is(err, ErrBodyTooLarge) {
+			t.Fatalf("Was expecting ErrBodyTooLarge got %v", err)
+		}
+	})
+}
+
+func TestRender(t *testing.T) {
+	t.Run("Record", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		if err := Render(rec, http.StatusCreated, testEmployee{Name: "John Doe", Age: 42}); err != nil {
+			t.Fatalf("Was not expecting an error got %s", err)
+		}
+
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("Render was expecting status %d got %d", http.StatusCreated, rec.Code)
+		}
+		if rec.Header().Get("Content-Type") != ContentType {
+			t.Fatalf("Render was expecting content type %s got %s", ContentType, rec.Header().Get("Content-Type"))
+		}
+
+		expected := "name: John Doe\nage: 42\n"
+		if rec.Body.String() != expected {
+			t.Fatalf("Render was expecting %q got %q", expected, rec.Body.String())
+		}
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		if err := Render(rec, http.StatusOK, testMarshalerError{}); err == nil {
+			t.Fatal("Was expecting an error from the marshaler")
+		}
+
+		if rec.Header().Get("Content-Type") != "" || rec.Body.Len() != 0 {
+			t.Fatalf("Render should not write on error, got %q", rec.Body.String())
+		}
+	})
+}
@@ -0,0 +1,185 @@
+// Package plainslog provides a log/slog handler that writes log records in
+// the plain format.
+package plainslog
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Handler writes each log record as a plain record: time, level, msg and
+// source when enabled, then the attributes. Groups are written as dotted key
+// prefixes and records are separated by a blank line, so the output decodes
+// with plain.Unmarshal into a slice.
+type Handler struct {
+	opts   slog.HandlerOptions
+	prefix string   // dotted key prefix of the open groups
+	groups []string // names of the open groups, passed to ReplaceAttr
+	attrs  string   // lines of the attributes added with WithAttrs
+	out    *output
+}
+
+// output is the writer shared by a handler and the handlers derived from it
+type output struct {
+	mu      sync.Mutex
+	w       io.Writer
+	written bool
+}
+
+// NewHandler returns a handler that writes to w. A nil opts uses the
+// defaults of slog.HandlerOptions.
+func NewHandler(w io.Writer, opts *slog.HandlerOptions) *Handler {
+	h := &Handler{out: &output{w: w}}
+	if opts != nil {
+		h.opts = *opts
+	}
+
+	return h
+}
+
+// Enabled reports whether the handler writes records at level.
+func (h *Handler) Enabled(_ context.Context, level slog.Level) bool {
+	minLevel := slog.LevelInfo
+	if h.opts.Level != nil {
+		minLevel = h.opts.Level.Level()
+	}
+
+	return level >= minLevel
+}
+
+// Handle writes r as a plain record.
+func (h *Handler) Handle(_ context.Context, r slog.Record) error {
+	var sb strings.Builder
+	if !r.Time.IsZero() {
+		h.appendAttr(&sb, "", nil, slog.Time(slog.TimeKey, r.Time))
+	}
+	h.appendAttr(&sb, "", nil, slog.Any(slog.LevelKey, r.Level))
+	if h.opts.AddSource && r.PC != 0 {
+		frames := runtime.CallersFrames([]uintptr{r.PC})
+		frame, _ := frames.Next()
+		source := &slog.Source{Function: frame.Function, File: frame.File, Line: frame.Line}
+		h.appendAttr(&sb, "", nil, slog.Any(slog.SourceKey, source))
+	}
+	h.appendAttr(&sb, "", nil, slog.String(slog.MessageKey, r.Message))
+
+	sb.WriteString(h.attrs)
+	r.Attrs(func(a slog.Attr) bool {
+		h.appendAttr(&sb, h.prefix, h.groups, a)
+		return true
+	})
+
+	h.out.mu.Lock()
+	defer h.out.mu.Unlock()
+
+	record := sb.String()
+	if h.out.written {
+		record = "\n" + record
+	}
+	if _, err := io.WriteString(h.out.w, record); err != nil {
+		return err
+	}
+	h.out.written = true
+
+	return nil
+}
+
+// WithAttrs returns a handler that writes attrs in every record, under the
+// groups opened so far.
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+
+	var sb strings.Builder
+	for _, a := range attrs {
+		h.appendAttr(&sb, h.prefix, h.groups, a)
+	}
+
+	clone := *h
+	clone.attrs = h.attrs + sb.String()
+	return &clone
+}
+
+// WithGroup returns a handler that writes the attributes that follow under
+// name.
+func (h *Handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	clone := *h
+	clone.prefix = h.prefix + name + "."
+	clone.groups = append(slices.Clip(h.groups), name)
+	return &clone
+}
+
+// appendAttr writes the lines of an attribute, prefix being the dotted key
+// of the groups it is in
+func (h *Handler) appendAttr(sb *strings.Builder, prefix string, groups []string, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+	if h.opts.ReplaceAttr != nil && a.Value.Kind() != slog.KindGroup {
+		a = h.opts.ReplaceAttr(groups, a)
+		a.Value = a.Value.Resolve()
+	}
+
+	// Empty attributes are ignored
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+
+	if a.Value.Kind() == slog.KindGroup {
+		attrs := a.Value.Group()
+		if len(attrs) == 0 {
+			return
+		}
+
+		// Groups without a key are inlined
+		if a.Key != "" {
+			prefix += a.Key + "."
+			groups = append(slices.Clip(groups), a.Key)
+		}
+		for _, ga := range attrs {
+			h.appendAttr(sb, prefix, groups, ga)
+		}
+		return
+	}
+
+	sb.WriteString(escapeTrailing(escape(prefix+a.Key)+": "+escape(formatValue(a.Value))) + "\n")
+}
+
+// formatValue returns the text of a value
+func formatValue(v slog.Value) string {
+	switch v.Kind() {
+	case slog.KindTime:
+		return v.Time().Format(time.RFC3339Nano)
+	case slog.KindAny:
+		if source, ok := v.Any().(*slog.Source); ok {
+			return fmt.Sprintf("%s:%d", source.File, source.Line)
+		}
+	}
+
+	return v.String()
+}
+
+// escape keeps a key or value on a single line. Other characters are
+// written as they are, since values are read back without unescaping.
+var escape = strings.NewReplacer("\r", `\r`, "\n", `\n`).Replace
+
+// escapeTrailing doubles the backslashes ending a line after a space or
+// tab, which plain would otherwise read as continuing the line
+func escapeTrailing(line string) string {
+	trimmed := strings.TrimRight(line, `\`)
+	n := len(line) - len(trimmed)
+	if n == 0 || !strings.HasSuffix(trimmed, " ") && !strings.HasSuffix(trimmed, "\t") {
+		return line
+	}
+
+	return line + strings.Repeat(`\`, n)
+}
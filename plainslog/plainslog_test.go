@@ -0,0 +1,122 @@
+package plainslog
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+	"testing/slogtest"
+	"time"
+
+	"github.com/brianvoe/plain"
+)
+
+type testLogLine struct {
+	Time    time.Time `plain:"time"`
+	Level   string    `plain:"level"`
+	Message string    `plain:"msg"`
+	User    struct {
+		ID   int    `plain:"id"`
+		Name string `plain:"name"`
+	} `plain:"user"`
+}
+
+func TestHandler(t *testing.T) {
+	t.Run("Slogtest", func(t *testing.T) {
+		var buf bytes.Buffer
+		results := func() []map[string]any {
+			var records []map[string]any
+			if err := plain.Unmarshal(buf.Bytes(), &records); err != nil {
+				t.Fatalf("Was not expecting an error got %s", err)
+			}
+			return records
+		}
+
+		if err := slogtest.TestHandler(NewHandler(&buf, nil), results); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("Records", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger := slog.New(NewHandler(&buf, &slog.HandlerOptions{
+			Level: slog.LevelDebug,
+			ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+				if a.Key == slog.TimeKey && len(groups) == 0 {
+					return slog.Attr{}
+				}
+				return a
+			},
+		}))
+
+		logger.Debug("started", "port", 8080)
+		logger.WithGroup("req").With("id", 7).Info("line one\nline two", slog.Group("user", "name", "John"), slog.Group("empty"))
+
+		expected := "level: DEBUG\nmsg: started\nport: 8080\n\nlevel: INFO\nmsg: line one\\nline two\nreq.id: 7\nreq.user.name: John\n"
+		if buf.String() != expected {
+			t.Fatalf("Handler was expecting %q\n got %q", expected, buf.String())
+		}
+	})
+
+	t.Run("Escaping", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger := slog.New(NewHandler(&buf, &slog.HandlerOptions{
+			ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+				if a.Key == slog.TimeKey && len(groups) == 0 {
+					return slog.Attr{}
+				}
+				return a
+			},
+		}))
+
+		logger.Info("line\nbreak", "path", `C:\tmp`, "dir", `C:\dir \`, "after", "next")
+
+		expected := "level: INFO\nmsg: line\\nbreak\npath: C:\\tmp\ndir: C:\\dir \\\\\nafter: next\n"
+		if buf.String() != expected {
+			t.Fatalf("Handler was expecting %q\n got %q", expected, buf.String())
+		}
+
+		var records []struct {
+			Path  string `plain:"path"`
+			Dir   string `plain:"dir"`
+			After string `plain:"after"`
+		}
+		if err := plain.Unmarshal(buf.Bytes(), &records); err != nil {
+			t.Fatalf("Was not expecting an error got %s", err)
+		}
+		if records[0].Path != `C:\tmp` || records[0].Dir != `C:\dir \` || records[0].After != "next" {
+			t.Fatalf("Unmarshal was expecting the logged values got %+v", records[0])
+		}
+	})
+
+	t.Run("Level", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger := slog.New(NewHandler(&buf, &slog.HandlerOptions{Level: slog.LevelWarn}))
+
+		logger.Info("skipped")
+		if buf.Len() != 0 {
+			t.Fatalf("Handler was expecting no output got %q", buf.String())
+		}
+	})
+
+	t.Run("Unmarshal", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger := slog.New(NewHandler(&buf, nil))
+
+		logger.Info("login", slog.Group("user", "id", 42, "name", "John Doe"))
+		logger.Warn("logout", slog.Group("user", "id", 43, "name", "Jane Doe"))
+
+		var lines []testLogLine
+		if err := plain.Unmarshal(buf.Bytes(), &lines); err != nil {
+			t.Fatalf("Was not expecting an error got %s", err)
+		}
+		if len(lines) != 2 {
+			t.Fatalf("Unmarshal was expecting 2 records got %d", len(lines))
+		}
+		if lines[1].Level != "WARN" || lines[1].Message != "logout" || lines[1].User.ID != 43 || lines[1].User.Name != "Jane Doe" {
+			t.Fatalf("Unmarshal got %+v", lines[1])
+		}
+		if time.Since(lines[0].Time) > time.Minute {
+			t.Fatalf("Unmarshal was expecting a recent time got %s", lines[0].Time)
+		}
+	})
+}
@@ -0,0 +1,178 @@
+package plain
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// propertyLines joins lines ending in an odd number of backslashes with the
+// line that follows, dropping the leading whitespace of the continuation.
+// Each logical line keeps the number of its first physical line.
+func propertyLines(lines []sourceLine) []sourceLine {
+	var joined []sourceLine
+	for i := 0; i < len(lines); i++ {
+		current := lines[i]
+		if isPropertyComment(strings.TrimSpace(current.text)) {
+			joined = append(joined, current)
+			continue
+		}
+
+		text := strings.TrimRight(current.text, "\r")
+		for continues(text) && i+1 < len(lines) {
+			i++
+			text = text[:len(text)-1] + strings.TrimLeft(strings.TrimRight(lines[i].text, "\r"), " \t\f")
+		}
+		if continues(text) {
+			text = text[:len(text)-1]
+		}
+
+		joined = append(joined, sourceLine{text: text, line: current.line})
+	}
+
+	return joined
+}
+
+// continues checks if a line ends in an unescaped backslash
+func continues(line string) bool {
+	n := 0
+	for n < len(line) && line[len(line)-1-n] == '\\' {
+		n++
+	}
+
+	return n%2 == 1
+}
+
+// isPropertyComment checks if a trimmed line is a # or ! comment
+func isPropertyComment(line string) bool {
+	return strings.HasPrefix(line, "#") || strings.HasPrefix(line, "!")
+}
+
+// splitProperty splits a line into its key and value. The key ends at the
+// first unescaped =, : or whitespace, and a single = or : may follow the
+// whitespace after the key. Trailing whitespace of the value is kept, as
+// Java does. On a malformed escape the raw key is returned with the error.
+func splitProperty(line string) (string, string, bool, error) {
+	line = strings.TrimLeft(line, " \t\f")
+	if line == "" {
+		return "", "", false, nil
+	}
+
+	end := len(line)
+	for i := 0; i < len(line); i++ {
+		if line[i] == '\\' {
+			i++
+			continue
+		}
+		if strings.IndexByte("=: \t\f", line[i]) >= 0 {
+			end = i
+			break
+		}
+	}
+
+	rest := strings.TrimLeft(line[end:], " \t\f")
+	if rest != "" && (rest[0] == '=' || rest[0] == ':') {
+		rest = strings.TrimLeft(rest[1:], " \t\f")
+	}
+
+	key, err := unescapeProperty(line[:end])
+	if err != nil {
+		return line[:end], "", true, err
+	}
+	value, err := unescapeProperty(rest)
+	if err != nil {
+		return line[:end], "", true, err
+	}
+
+	return key, value, true, nil
+}
+
+// unescapeProperty resolves backslash escapes, \uXXXX included
+func unescapeProperty(s string) (string, error) {
+	if !strings.Contains(s, "\\") {
+		return s, nil
+	}
+
+	var sb strings.Builder
+	var high rune
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			sb.WriteByte(s[i])
+			continue
+		}
+
+		i++
+		switch s[i] {
+		case 't':
+			sb.WriteByte('\t')
+		case 'n':
+			sb.WriteByte('\n')
+		case 'r':
+			sb.WriteByte('\r')
+		case 'f':
+			sb.WriteByte('\f')
+		case 'u':
+			if i+5 > len(s) {
+				return "", fmt.Errorf("malformed \\u escape: %s", s[i-1:])
+			}
+			code, err := strconv.ParseUint(s[i+1:i+5], 16, 16)
+			if err != nil {
+				return "", fmt.Errorf("malformed \\u escape: %s", s[i-1:i+5])
+			}
+			i += 4
+
+			// Characters above U+FFFF are written as a surrogate pair
+			r := rune(code)
+			switch {
+			case r >= 0xD800 && r < 0xDC00:
+				high = r
+				continue
+			case r >= 0xDC00 && r < 0xE000 && high != 0:
+				r = (high-0xD800)<<10 + (r - 0xDC00) + 0x10000
+			}
+			high = 0
+			sb.WriteRune(r)
+		default:
+			sb.WriteByte(s[i])
+		}
+	}
+
+	return sb.String(), nil
+}
+
+// escapeProperty escapes a key or value so splitProperty reads it back.
+// Characters outside ASCII are written as \uXXXX so files stay valid in the
+// ISO 8859-1 encoding Java reads by default.
+func escapeProperty(s string, key bool) string {
+	var sb strings.Builder
+	for i, r := range s {
+		switch {
+		case r == '\\':
+			sb.WriteString(`\\`)
+		case r == '\t':
+			sb.WriteString(`\t`)
+		case r == '\n':
+			sb.WriteString(`\n`)
+		case r == '\r':
+			sb.WriteString(`\r`)
+		case r == '\f':
+			sb.WriteString(`\f`)
+		case r == ' ' && (key || i == 0):
+			sb.WriteString(`\ `)
+		case key && strings.ContainsRune("=:#!", r):
+			sb.WriteByte('\\')
+			sb.WriteRune(r)
+		case r < 0x20 || r > 0x7e:
+			if r > 0xFFFF {
+				r -= 0x10000
+				fmt.Fprintf(&sb, `\u%04X\u%04X`, 0xD800+(r>>10), 0xDC00+(r&0x3FF))
+				continue
+			}
+			fmt.Fprintf(&sb, `\u%04X`, r)
+		default:
+			sb.WriteRune(r)
+		}
+	}
+
+	return sb.String()
+}
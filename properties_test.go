@@ -0,0 +1,112 @@
+package plain
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type TestProperties struct {
+	Name    string        `plain:"app.name"`
+	Message string        `plain:"app.message"`
+	Hosts   []string      `plain:"app.hosts"`
+	DB      TestDialectDB `plain:"db"`
+}
+
+func TestUnmarshalProperties(t *testing.T) {
+	t.Run("Separators and comments", func(t *testing.T) {
+		var result TestProperties
+		data := "# comment\n! also a comment\napp.name=api\napp.message: hello world\ndb.host localhost\n  db.pool_size = 5\n"
+		if err := UnmarshalDialect([]byte(data), &result, DialectProperties); err != nil {
+			t.Fatalf("Was not expecting an error got %s", err)
+		}
+
+		expected := TestProperties{Name: "api", Message: "hello world", DB: TestDialectDB{Host: "localhost", PoolSize: 5}}
+		if !reflect.DeepEqual(result, expected) {
+			t.Fatalf("UnmarshalDialect was expecting %+v\n got %+v", expected, result)
+		}
+	})
+
+	t.Run("Continuations", func(t *testing.T) {
+		var result TestProperties
+		data := "app.hosts = a, \\\n    b, \\\n    c\napp.name = ends with \\\\\n"
+		if err := UnmarshalDialect([]byte(data), &result, DialectProperties); err != nil {
+			t.Fatalf("Was not expecting an error got %s", err)
+		}
+
+		if !reflect.DeepEqual(result.Hosts, []string{"a", "b", "c"}) {
+			t.Fatalf("UnmarshalDialect was expecting [a b c] got %v", result.Hosts)
+		}
+		if result.Name != `ends with \` {
+			t.Fatalf("UnmarshalDialect was expecting an escaped backslash got %q", result.Name)
+		}
+	})
+
+	t.Run("Escapes", func(t *testing.T) {
+		var result TestProperties
+		data := "app\\.name = caf\\u00e9 \\uD83D\\uDE00\napp.message = a\\tb\\nc \\= d\n"
+		if err := UnmarshalDialect([]byte(data), &result, DialectProperties); err != nil {
+			t.Fatalf("Was not expecting an error got %s", err)
+		}
+
+		if result.Name != "café 😀" {
+			t.Fatalf("UnmarshalDialect was expecting %q got %q", "café 😀", result.Name)
+		}
+		if result.Message != "a\tb\nc = d" {
+			t.Fatalf("UnmarshalDialect was expecting %q got %q", "a\tb\nc = d", result.Message)
+		}
+	})
+
+	t.Run("Malformed escape", func(t *testing.T) {
+		var result TestProperties
+		err := UnmarshalDialect([]byte("app.name = ok\napp.message = \\u12"), &result, DialectProperties)
+
+		var decodeErr *DecodeError
+		if !errors.As(err, &decodeErr) {
+			t.Fatalf("Was expecting a DecodeError got %v", err)
+		}
+		if decodeErr.Line != 2 || decodeErr.Key != "app.message" {
+			t.Fatalf("Was expecting line 2 app.message got %d %s", decodeErr.Line, decodeErr.Key)
+		}
+	})
+
+	t.Run("Error line after continuation", func(t *testing.T) {
+		var result TestProperties
+		err := UnmarshalDialect([]byte("app.hosts = a, \\\n  b\ndb.pool_size = x"), &result, DialectProperties)
+
+		var decodeErr *DecodeError
+		if !errors.As(err, &decodeErr) {
+			t.Fatalf("Was expecting a DecodeError got %v", err)
+		}
+		if decodeErr.Line != 3 {
+			t.Fatalf("Was expecting line 3 got %d", decodeErr.Line)
+		}
+	})
+}
+
+func TestMarshalProperties(t *testing.T) {
+	value := TestProperties{
+		Name:    "café",
+		Message: " a=b: #c\nd\\",
+		Hosts:   []string{"a", "b"},
+		DB:      TestDialectDB{Host: "localhost", PoolSize: 5},
+	}
+
+	resp, err := MarshalDialect(value, DialectProperties)
+	if err != nil {
+		t.Fatalf("Was not expecting an error got %s", err)
+	}
+
+	expected := "app.name=caf\\u00E9\napp.message=\\ a=b: #c\\nd\\\\\napp.hosts=a,b\ndb.host=localhost\ndb.pool_size=5"
+	if string(resp) != expected {
+		t.Fatalf("MarshalDialect was expecting %q\n got %q", expected, resp)
+	}
+
+	var result TestProperties
+	if err := UnmarshalDialect(resp, &result, DialectProperties); err != nil {
+		t.Fatalf("Was not expecting an error got %s", err)
+	}
+	if !reflect.DeepEqual(result, value) {
+		t.Fatalf("Round trip was expecting %+v\n got %+v", value, result)
+	}
+}
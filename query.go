@@ -0,0 +1,290 @@
+package plain
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// A Match is a value found by Query.
+type Match struct {
+	Record int    // index of the record the value was found in
+	Path   string // full dotted path of the value
+	Value  any    // a string, a []any list or a map[string]any of nested keys
+}
+
+// Query decodes data into generic records and returns the values matching
+// expr, in record order.
+//
+// An expression is a dotted path such as sub.name where each segment is a
+// key, a list index (tags.0 or tags[0]) or * to match every key or element.
+// A filter in brackets keeps only the values it holds for, e.g.
+// [age > 30].name or addresses.*[city == "New York"].zip. Filters compare a
+// dotted path relative to the value with ==, !=, <, <=, > or >=, numerically
+// when both sides are numbers. A filter without an operator, such as
+// [email], checks that the path exists.
+func Query(data []byte, expr string) ([]Match, error) {
+	// Check the expression before decoding
+	if _, err := parseQuery(expr); err != nil {
+		return nil, err
+	}
+
+	var records []map[string]any
+	if err := Unmarshal(data, &records); err != nil {
+		return nil, err
+	}
+
+	return QueryRecords(records, expr)
+}
+
+// QueryRecords is like Query but runs expr over records that are already
+// decoded.
+func QueryRecords(records []map[string]any, expr string) ([]Match, error) {
+	steps, err := parseQuery(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []Match
+	for i, record := range records {
+		current := []Match{{Record: i, Value: record}}
+		for _, step := range steps {
+			current = step.apply(current)
+		}
+
+		matches = append(matches, current...)
+	}
+
+	return matches, nil
+}
+
+type queryStepKind int
+
+const (
+	queryKey queryStepKind = iota
+	queryWildcard
+	queryFilter
+)
+
+type queryStep struct {
+	kind queryStepKind
+	key  string
+
+	// filter
+	path  string
+	op    string
+	value string
+}
+
+var queryOps = []string{"==", "!=", ">=", "<=", ">", "<", "="}
+
+// parseQuery splits an expression into its steps
+func parseQuery(expr string) ([]queryStep, error) {
+	var steps []queryStep
+	invalid := func(msg string) error {
+		return fmt.Errorf("invalid query %q: %s", expr, msg)
+	}
+
+	for i := 0; i < len(expr); {
+		switch expr[i] {
+		case '.':
+			i++
+		case '[':
+			end := closingBracket(expr, i)
+			if end < 0 {
+				return nil, invalid("missing ]")
+			}
+
+			content := strings.TrimSpace(expr[i+1 : end])
+			i = end + 1
+			if content == "" {
+				return nil, invalid("empty brackets")
+			}
+
+			// [n] is the same as .n
+			if _, err := strconv.Atoi(content); err == nil {
+				steps = append(steps, queryStep{kind: queryKey, key: content})
+				continue
+			}
+			if content == "*" {
+				steps = append(steps, queryStep{kind: queryWildcard})
+				continue
+			}
+
+			step := queryStep{kind: queryFilter, path: content}
+			for _, op := range queryOps {
+				if path, value, found := strings.Cut(content, op); found {
+					step.path = strings.TrimSpace(path)
+					step.op = op
+					step.value = unquote(strings.TrimSpace(value))
+					break
+				}
+			}
+			if step.path == "" {
+				return nil, invalid("filter without a key")
+			}
+			steps = append(steps, step)
+		default:
+			end := strings.IndexAny(expr[i:], ".[")
+			if end < 0 {
+				end = len(expr)
+			} else {
+				end += i
+			}
+
+			key := strings.TrimSpace(expr[i:end])
+			i = end
+			if key == "*" {
+				steps = append(steps, queryStep{kind: queryWildcard})
+			} else if key != "" {
+				steps = append(steps, queryStep{kind: queryKey, key: key})
+			}
+		}
+	}
+
+	if len(steps) == 0 {
+		return nil, errors.New("empty query")
+	}
+
+	return steps, nil
+}
+
+// closingBracket returns the index of the ] matching the [ at start, skipping
+// quoted strings
+func closingBracket(expr string, start int) int {
+	var quote byte
+	for i := start + 1; i < len(expr); i++ {
+		switch {
+		case quote != 0:
+			if expr[i] == quote {
+				quote = 0
+			}
+		case expr[i] == '"' || expr[i] == '\'':
+			quote = expr[i]
+		case expr[i] == ']':
+			return i
+		}
+	}
+
+	return -1
+}
+
+func unquote(value string) string {
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		return value[1 : len(value)-1]
+	}
+
+	return value
+}
+
+// apply runs a step over the current matches
+func (s queryStep) apply(current []Match) []Match {
+	var next []Match
+	for _, m := range current {
+		switch s.kind {
+		case queryKey:
+			if value, ok := child(m.Value, s.key); ok {
+				next = append(next, Match{Record: m.Record, Path: joinPath(m.Path, s.key), Value: value})
+			}
+		case queryWildcard:
+			switch value := m.Value.(type) {
+			case map[string]any:
+				keys := make([]string, 0, len(value))
+				for key := range value {
+					keys = append(keys, key)
+				}
+				sort.Strings(keys)
+
+				for _, key := range keys {
+					next = append(next, Match{Record: m.Record, Path: joinPath(m.Path, key), Value: value[key]})
+				}
+			case []any:
+				for i, element := range value {
+					next = append(next, Match{Record: m.Record, Path: joinPath(m.Path, strconv.Itoa(i)), Value: element})
+				}
+			}
+		case queryFilter:
+			if s.matches(m.Value) {
+				next = append(next, m)
+			}
+		}
+	}
+
+	return next
+}
+
+// matches checks the filter against a value
+func (s queryStep) matches(value any) bool {
+	for _, key := range strings.Split(s.path, ".") {
+		var ok bool
+		if value, ok = child(value, key); !ok {
+			return false
+		}
+	}
+	if s.op == "" {
+		return true
+	}
+
+	str, ok := value.(string)
+	if !ok {
+		return false
+	}
+
+	cmp := strings.Compare(str, s.value)
+	left, leftErr := strconv.ParseFloat(str, 64)
+	right, rightErr := strconv.ParseFloat(s.value, 64)
+	if leftErr == nil && rightErr == nil {
+		switch {
+		case left < right:
+			cmp = -1
+		case left > right:
+			cmp = 1
+		default:
+			cmp = 0
+		}
+	}
+
+	switch s.op {
+	case "==", "=":
+		return cmp == 0
+	case "!=":
+		return cmp != 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	}
+
+	return false
+}
+
+// child returns a key of a map or an index of a list
+func child(value any, key string) (any, bool) {
+	switch value := value.(type) {
+	case map[string]any:
+		v, ok := value[key]
+		return v, ok
+	case []any:
+		i, err := strconv.Atoi(key)
+		if err != nil || i < 0 || i >= len(value) {
+			return nil, false
+		}
+		return value[i], true
+	}
+
+	return nil, false
+}
+
+func joinPath(parent, key string) string {
+	if parent == "" {
+		return key
+	}
+
+	return parent + "." + key
+}
@@ -0,0 +1,138 @@
+package plain
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestQuery(t *testing.T) {
+	data := []byte(`name: John Doe
+age: 42
+tags: [admin, dev]
+addresses.home.city: New York
+addresses.home.zip: 10001
+addresses.work.city: Boston
+
+name: Jane Doe
+age: 25
+tags: [dev]
+addresses.home.city: Chicago
+
+name: Jim Doe
+age: 9`)
+
+	tests := []struct {
+		name     string
+		expr     string
+		expected []Match
+	}{
+		{
+			name: "Path",
+			expr: "addresses.home.city",
+			expected: []Match{
+				{Record: 0, Path: "addresses.home.city", Value: "New York"},
+				{Record: 1, Path: "addresses.home.city", Value: "Chicago"},
+			},
+		},
+		{
+			name: "Wildcard",
+			expr: "addresses.*.city",
+			expected: []Match{
+				{Record: 0, Path: "addresses.home.city", Value: "New York"},
+				{Record: 0, Path: "addresses.work.city", Value: "Boston"},
+				{Record: 1, Path: "addresses.home.city", Value: "Chicago"},
+			},
+		},
+		{
+			name: "Index",
+			expr: "tags[1]",
+			expected: []Match{
+				{Record: 0, Path: "tags.1", Value: "dev"},
+			},
+		},
+		{
+			name: "Dotted index",
+			expr: "tags.0",
+			expected: []Match{
+				{Record: 0, Path: "tags.0", Value: "admin"},
+				{Record: 1, Path: "tags.0", Value: "dev"},
+			},
+		},
+		{
+			name: "Numeric filter",
+			expr: "[age > 20].name",
+			expected: []Match{
+				{Record: 0, Path: "name", Value: "John Doe"},
+				{Record: 1, Path: "name", Value: "Jane Doe"},
+			},
+		},
+		{
+			name: "String filter",
+			expr: `addresses.*[city == "Boston"].city`,
+			expected: []Match{
+				{Record: 0, Path: "addresses.work.city", Value: "Boston"},
+			},
+		},
+		{
+			name: "Exists filter",
+			expr: "[addresses.home.zip].name",
+			expected: []Match{
+				{Record: 0, Path: "name", Value: "John Doe"},
+			},
+		},
+		{
+			name: "List wildcard",
+			expr: "tags.*",
+			expected: []Match{
+				{Record: 0, Path: "tags.0", Value: "admin"},
+				{Record: 0, Path: "tags.1", Value: "dev"},
+				{Record: 1, Path: "tags.0", Value: "dev"},
+			},
+		},
+		{
+			name: "Map value",
+			expr: "[name != Jane Doe].addresses.work",
+			expected: []Match{
+				{Record: 0, Path: "addresses.work", Value: map[string]any{"city": "Boston"}},
+			},
+		},
+		{
+			name:     "No match",
+			expr:     "missing",
+			expected: nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			matches, err := Query(data, test.expr)
+			if err != nil {
+				t.Fatalf("Was not expecting an error got %s", err)
+			}
+			if !reflect.DeepEqual(matches, test.expected) {
+				t.Fatalf("Query %s was expecting %v\n got %v", test.expr, test.expected, matches)
+			}
+		})
+	}
+
+	t.Run("Records", func(t *testing.T) {
+		records := []map[string]any{{"name": "John Doe"}, {"name": "Jane Doe"}}
+		matches, err := QueryRecords(records, "name")
+		if err != nil {
+			t.Fatalf("Was not expecting an error got %s", err)
+		}
+
+		expected := []Match{{Record: 0, Path: "name", Value: "John Doe"}, {Record: 1, Path: "name", Value: "Jane Doe"}}
+		if !reflect.DeepEqual(matches, expected) {
+			t.Fatalf("QueryRecords was expecting %v\n got %v", expected, matches)
+		}
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		for _, expr := range []string{"", "name[age > 3", "[]", "[> 3]"} {
+			if _, err := Query(data, expr); err == nil {
+				t.Fatalf("Query %q was expecting an error", expr)
+			}
+		}
+	})
+}
@@ -0,0 +1,74 @@
+package plain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"unicode/utf8"
+)
+
+// A Redactor returns what to write in place of the value of a secret field.
+// The key is the full key of the value being written.
+//
+// Fields are secret when tagged with the secret or redact option. The
+// option redact=mask, redact=hash or redact=last4 picks RedactMask,
+// RedactHash or RedactLast4 for a field, other secret fields use the
+// Encoder's redactor, RedactMask by default. Every value written for a
+// secret struct, slice or map field is redacted.
+type Redactor func(key, value string) string
+
+// RedactMask writes *** for every value.
+func RedactMask(key, value string) string {
+	return "***"
+}
+
+// RedactHash writes the first 12 hex digits of the SHA-256 of the value, so
+// equal values can be matched without being shown. Short or guessable
+// values can still be recovered from their hash.
+func RedactHash(key, value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return "sha256:" + hex.EncodeToString(sum[:6])
+}
+
+// RedactLast4 writes *** followed by the last four characters of values of
+// at least eight characters, and *** for shorter ones.
+func RedactLast4(key, value string) string {
+	if utf8.RuneCountInString(value) < 8 {
+		return "***"
+	}
+
+	runes := []rune(value)
+	return "***" + string(runes[len(runes)-4:])
+}
+
+// redactor returns the redactor for a field with the given tag options, nil
+// if the field is not secret or secrets are revealed
+func (e *encodeState) redactor(opts tagOptions) (Redactor, error) {
+	form, redact := opts["redact"]
+	if !redact && !opts.has("secret") {
+		return nil, nil
+	}
+
+	var redactor Redactor
+	switch form {
+	case "":
+		redactor = e.redact
+		if redactor == nil {
+			redactor = RedactMask
+		}
+	case "mask":
+		redactor = RedactMask
+	case "hash":
+		redactor = RedactHash
+	case "last4":
+		redactor = RedactLast4
+	default:
+		return nil, errors.New("invalid redact option: " + form)
+	}
+
+	if e.reveal {
+		return nil, nil
+	}
+
+	return redactor, nil
+}
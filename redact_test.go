@@ -0,0 +1,118 @@
+package plain
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+type TestRedactAuth struct {
+	User     string `plain:"user"`
+	Password string `plain:"password,secret"`
+}
+
+type TestRedact struct {
+	Name   string            `plain:"name"`
+	Token  string            `plain:"token,redact"`
+	Card   string            `plain:"card,redact=last4"`
+	Key    string            `plain:"key,redact=hash"`
+	Auth   TestRedactAuth    `plain:"auth"`
+	Users  []TestRedactAuth  `plain:"users"`
+	Keys   []string          `plain:"keys,secret"`
+	Labels map[string]string `plain:"labels,secret"`
+	DB     TestRedactAuth    `plain:"db,secret"`
+}
+
+func TestRedaction(t *testing.T) {
+	value := TestRedact{
+		Name:   "app",
+		Token:  "abc123",
+		Card:   "4111111111111111",
+		Key:    "hunter2",
+		Auth:   TestRedactAuth{User: "admin", Password: "pw"},
+		Users:  []TestRedactAuth{{User: "a", Password: "pa"}},
+		Keys:   []string{"k1", "k2"},
+		Labels: map[string]string{"env": "prod"},
+		DB:     TestRedactAuth{User: "root", Password: "pw"},
+	}
+
+	t.Run("Marshal", func(t *testing.T) {
+		resp, err := Marshal(value)
+		if err != nil {
+			t.Fatalf("Was not expecting an error got %s", err)
+		}
+
+		expected := strings.Join([]string{
+			"name: app",
+			"token: ***",
+			"card: ***1111",
+			"key: " + RedactHash("key", "hunter2"),
+			"auth.user: admin",
+			"auth.password: ***",
+			"users: [user: a\npassword: ***\n]",
+			"keys: ***",
+			"labels.env: ***",
+			"db.user: ***",
+			"db.password: ***",
+		}, "\n")
+		if string(resp) != expected {
+			t.Fatalf("Marshal was expecting %q\n got %q", expected, resp)
+		}
+	})
+
+	t.Run("Redactor", func(t *testing.T) {
+		var buf bytes.Buffer
+		enc := NewEncoder(&buf)
+		enc.SetRedactor(func(key, value string) string { return "<" + key + ">" })
+		if err := enc.Encode(value.Auth); err != nil {
+			t.Fatalf("Was not expecting an error got %s", err)
+		}
+
+		expected := "user: admin\npassword: <password>\n"
+		if buf.String() != expected {
+			t.Fatalf("Encoder was expecting %q\n got %q", expected, buf.String())
+		}
+	})
+
+	t.Run("Reveal", func(t *testing.T) {
+		var buf bytes.Buffer
+		enc := NewEncoder(&buf)
+		enc.RevealSecrets()
+		if err := enc.Encode(value.Auth); err != nil {
+			t.Fatalf("Was not expecting an error got %s", err)
+		}
+
+		expected := "user: admin\npassword: pw\n"
+		if buf.String() != expected {
+			t.Fatalf("Encoder was expecting %q\n got %q", expected, buf.String())
+		}
+	})
+
+	t.Run("Table", func(t *testing.T) {
+		resp, err := MarshalTable([]TestRedactAuth{{User: "a", Password: "secret"}})
+		if err != nil {
+			t.Fatalf("Was not expecting an error got %s", err)
+		}
+		if strings.Contains(string(resp), "secret") {
+			t.Fatalf("MarshalTable was expecting the password redacted got %q", resp)
+		}
+	})
+
+	t.Run("Forms", func(t *testing.T) {
+		if RedactLast4("card", "1234") != "***" {
+			t.Fatal("RedactLast4 should mask short values")
+		}
+		if h := RedactHash("key", "a"); !strings.HasPrefix(h, "sha256:") || len(h) != 19 {
+			t.Fatalf("RedactHash was expecting a short sha256 got %q", h)
+		}
+	})
+
+	t.Run("Invalid form", func(t *testing.T) {
+		var invalid struct {
+			Key string `plain:"key,redact=rot13"`
+		}
+		if _, err := Marshal(invalid); err == nil || err.Error() != "key: invalid redact option: rot13" {
+			t.Fatalf("Was expecting an error for an invalid redact option got %v", err)
+		}
+	})
+}
@@ -0,0 +1,189 @@
+package plain
+
+import (
+	"io"
+	"io/fs"
+	"strings"
+)
+
+// An Encoder writes plain records to an output stream.
+type Encoder struct {
+	w       io.Writer
+	opts    encodeOptions
+	written bool
+	header  *tableHeader // header written in the table layout
+}
+
+// NewEncoder returns a new encoder that writes to w.
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{w: w}
+}
+
+// SetDialect sets the tokens used to write keys, lists and records.
+// Separators left empty fall back to those of DialectDefault.
+func (e *Encoder) SetDialect(dialect Dialect) {
+	e.opts.dialect = dialect.withDefaults()
+}
+
+// SetAlign pads keys so the values of each record start in the same column.
+func (e *Encoder) SetAlign(align bool) {
+	e.opts.align = align
+}
+
+// SetWrap wraps values longer than width columns at spaces, the way
+// MarshalAligned does. A width of 0 turns wrapping off.
+func (e *Encoder) SetWrap(width int) {
+	e.opts.wrap = width
+}
+
+// SetLayout sets how slices of records are written. In the table layout
+// the first Encode writes the header and later calls add rows under it, so
+// their columns and the widths of their cells must fit that header.
+func (e *Encoder) SetLayout(layout Layout) {
+	e.opts.layout = layout
+}
+
+// SetSortKeys writes the fields of structs sorted by key instead of in
+// declaration order, at every level. Fields tagged with order=N still come
+// first. Map keys are always sorted.
+func (e *Encoder) SetSortKeys(sortKeys bool) {
+	e.opts.sortKeys = sortKeys
+}
+
+// SetMaxDepth sets how deep values may nest, DefaultMaxDepth when depth is
+// 0. Encode returns ErrMaxDepth for deeper values.
+func (e *Encoder) SetMaxDepth(depth int) {
+	e.opts.maxDepth = depth
+}
+
+// SetColor colors keys and values with theme when the writer is a terminal
+// and the NO_COLOR environment variable is not set. Otherwise the output is
+// the same as without color.
+func (e *Encoder) SetColor(theme Theme) {
+	e.opts.theme = &theme
+}
+
+// SetRedactor sets how secret fields without a redact form are written.
+func (e *Encoder) SetRedactor(redactor Redactor) {
+	e.opts.redact = redactor
+}
+
+// RevealSecrets writes secret fields as they are. Only use it for outputs
+// that are trusted with the secrets.
+func (e *Encoder) RevealSecrets() {
+	e.opts.reveal = true
+}
+
+// Encode writes the plain encoding of v to the stream followed by a newline.
+// Successive calls are separated by the record separator, a blank line by
+// default, so the stream decodes as a slice of records.
+func (e *Encoder) Encode(v any) error {
+	opts := e.opts
+	if opts.theme != nil && !colorEnabled(e.w) {
+		opts.theme = nil
+	}
+
+	enc := newEncodeState(opts)
+	if opts.layout == LayoutTable {
+		enc.header = e.header
+	}
+	data, err := enc.marshal(v)
+	if err != nil {
+		return err
+	}
+
+	// Tables after the first continue it without a header
+	continued := opts.layout == LayoutTable && e.header != nil
+	if opts.layout == LayoutTable {
+		e.header = enc.header
+	}
+	if e.written && !continued {
+		data = append([]byte(strings.TrimPrefix(enc.dialect.RecordSeparator, "\n")), data...)
+	}
+	data = append(data, '\n')
+
+	if _, err := e.w.Write(data); err != nil {
+		return err
+	}
+	e.written = true
+
+	return nil
+}
+
+// A Decoder reads plain records from an input stream.
+type Decoder struct {
+	r    io.Reader
+	opts decodeOptions
+}
+
+// NewDecoder returns a new decoder that reads from r.
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{r: r}
+}
+
+// CollectErrors makes Decode continue past values that fail to decode. Every
+// field that decodes is still set, and the failures are returned together as
+// DecodeErrors.
+func (d *Decoder) CollectErrors() {
+	d.opts.collectErrors = true
+}
+
+// SetDialect sets the tokens used to read keys, lists and records. Keys
+// are matched without regard to case in every dialect.
+func (d *Decoder) SetDialect(dialect Dialect) {
+	d.opts.dialect = dialect.withDefaults()
+}
+
+// Interpolate expands references in values. ${key} is replaced with the
+// value a key defined above it in the same record has at that line, matched
+// without regard to case, and ${env:NAME} with the value lookup returns for
+// NAME. Pass os.LookupEnv to read the process environment, or nil to leave
+// env references undefined. $${ writes a literal ${.
+func (d *Decoder) Interpolate(lookup func(string) (string, bool)) {
+	d.opts.interpolate = true
+	d.opts.lookup = lookup
+}
+
+// IncludeFS resolves @include path lines through fsys. The keys of the
+// included file are read in place of the line, so keys after it override
+// them. Paths are relative to the including file, the decoded input being at
+// the root of fsys, and may not leave fsys. Without a file system @include
+// lines are skipped like other lines without a key.
+func (d *Decoder) IncludeFS(fsys fs.FS) {
+	d.opts.fsys = fsys
+}
+
+// SetMaxDepth sets how deep keys may nest, DefaultMaxDepth when depth is 0.
+// Deeper keys fail to decode with ErrMaxDepth. A lower MaxKeyDepth set with
+// SetLimits takes its place.
+func (d *Decoder) SetMaxDepth(depth int) {
+	d.opts.maxDepth = depth
+}
+
+// SetLayout sets how slices of records are read.
+func (d *Decoder) SetLayout(layout Layout) {
+	d.opts.layout = layout
+}
+
+// SetLimits bounds the input Decode accepts. Input over a limit fails with
+// a *LimitError before more of it is read or split.
+func (d *Decoder) SetLimits(limits Limits) {
+	d.opts.limits = limits
+}
+
+// Decode reads the rest of the stream and stores the result in the value
+// pointed to by v, following the same rules as Unmarshal.
+func (d *Decoder) Decode(v any) error {
+	r := d.r
+	if limit := d.opts.limits.MaxBytes; limit > 0 {
+		// Read one byte past the limit to tell a payload over it apart
+		r = io.LimitReader(r, limit+1)
+	}
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	return newDecodeState(d.opts).unmarshal(data, v)
+}
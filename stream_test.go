@@ -0,0 +1,125 @@
+package plain
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestEncoder(t *testing.T) {
+	t.Run("Single", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := NewEncoder(&buf).Encode(TestData{Name: "test", Age: 35}); err != nil {
+			t.Fatalf("Was not expecting an error got %s", err)
+		}
+
+		expected := "name: test\nage: 35\nactive: false\nbalance: 0\n"
+		if buf.String() != expected {
+			t.Fatalf("Encoder was expecting %q\n got %q", expected, buf.String())
+		}
+	})
+
+	t.Run("Multiple", func(t *testing.T) {
+		var buf bytes.Buffer
+		enc := NewEncoder(&buf)
+		for _, name := range []string{"test1", "test2"} {
+			if err := enc.Encode(TestStructSlice{Names: []string{name}}); err != nil {
+				t.Fatalf("Was not expecting an error got %s", err)
+			}
+		}
+
+		expected := "names: [test1]\n\nnames: [test2]\n"
+		if buf.String() != expected {
+			t.Fatalf("Encoder was expecting %q\n got %q", expected, buf.String())
+		}
+	})
+
+	t.Run("Marshaler error", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := NewEncoder(&buf).Encode(TestMarshalerError{}); err == nil {
+			t.Fatal("Was expecting an error from the marshaler")
+		}
+		if buf.Len() != 0 {
+			t.Fatalf("Encoder should not write on error, got %q", buf.String())
+		}
+	})
+}
+
+type TestMarshalerError struct{}
+
+func (TestMarshalerError) MarshalPlain() ([]byte, error) {
+	return nil, errors.New("marshal error")
+}
+
+func TestDecoder(t *testing.T) {
+	var result []TestData
+	data := "name: John Doe\nage: 30\n\nname: Jane Doe\nage: 25\n"
+	if err := NewDecoder(strings.NewReader(data)).Decode(&result); err != nil {
+		t.Fatalf("Failed to decode: %v", err)
+	}
+
+	expected := []TestData{{Name: "John Doe", Age: 30}, {Name: "Jane Doe", Age: 25}}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Decoder: got %v, want %v", result, expected)
+	}
+}
+
+func TestDecoderCollectErrors(t *testing.T) {
+	data := "name: John Doe\nage: thirty\nactive: yes\nbalance: 1.5\n\nname: Jane Doe\n# comment\nage: 2x\n"
+
+	t.Run("First error", func(t *testing.T) {
+		var result []TestData
+		err := NewDecoder(strings.NewReader(data)).Decode(&result)
+
+		var decodeErr *DecodeError
+		if !errors.As(err, &decodeErr) {
+			t.Fatalf("Was expecting a DecodeError got %v", err)
+		}
+		if decodeErr.Line != 2 || decodeErr.Key != "age" {
+			t.Fatalf("Was expecting an error for age on line 2 got %s", decodeErr)
+		}
+	})
+
+	t.Run("All errors", func(t *testing.T) {
+		var result []TestData
+		dec := NewDecoder(strings.NewReader(data))
+		dec.CollectErrors()
+		err := dec.Decode(&result)
+
+		var errs DecodeErrors
+		if !errors.As(err, &errs) {
+			t.Fatalf("Was expecting DecodeErrors got %v", err)
+		}
+
+		expected := "line 2: age: strconv.ParseInt: parsing \"thirty\": invalid syntax\n" +
+			"line 3: active: strconv.ParseBool: parsing \"yes\": invalid syntax\n" +
+			"line 8: age: strconv.ParseInt: parsing \"2x\": invalid syntax"
+		if errs.Error() != expected {
+			t.Fatalf("Was expecting %q\n got %q", expected, errs.Error())
+		}
+		if !errors.Is(err, strconv.ErrSyntax) {
+			t.Fatal("Was expecting errors.Is to find strconv.ErrSyntax")
+		}
+
+		// Every value that parsed is still set
+		expectedResult := []TestData{{Name: "John Doe", Balance: 1.5}, {Name: "Jane Doe"}}
+		if !reflect.DeepEqual(result, expectedResult) {
+			t.Errorf("Decoder: got %v, want %v", result, expectedResult)
+		}
+	})
+
+	t.Run("With validation", func(t *testing.T) {
+		var result TestValidateSub
+		dec := NewDecoder(strings.NewReader("zip: 123"))
+		dec.CollectErrors()
+		err := dec.Decode(&result)
+
+		var validationErrs ValidationErrors
+		if !errors.As(err, &validationErrs) || len(validationErrs) != 2 {
+			t.Fatalf("Was expecting 2 validation errors got %v", err)
+		}
+	})
+}
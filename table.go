@@ -0,0 +1,263 @@
+package plain
+
+import (
+	"errors"
+	"reflect"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// Layout selects how a slice of records is written and read.
+type Layout int
+
+const (
+	// LayoutRecords writes each record as key/value lines, records separated
+	// by the record separator of the dialect.
+	LayoutRecords Layout = iota
+
+	// LayoutTable writes a slice of flat structs or maps as aligned columns
+	// under a header row of keys. Nested fields are columns with dotted
+	// names. Values may not span lines.
+	LayoutTable
+
+	// LayoutMarkdown writes a Markdown table: a slice of records with a
+	// column per key, any other value as a table of keys and values. It can
+	// only be written.
+	LayoutMarkdown
+)
+
+// MarshalTable writes a slice of structs or maps as a table.
+func MarshalTable(v any) ([]byte, error) {
+	return newEncodeState(encodeOptions{layout: LayoutTable}).marshal(v)
+}
+
+// UnmarshalTable reads a table written by MarshalTable into the slice
+// pointed to by v. Cells are found by the columns of the header, so the
+// values of a column must start where its name does. Empty cells are left
+// out, the same as a key missing from a record.
+func UnmarshalTable(data []byte, v any) error {
+	return newDecodeState(decodeOptions{layout: LayoutTable}).unmarshal(data, v)
+}
+
+// marshalTable writes the rows of every element of a slice as columns
+func (e *encodeState) marshalTable(val reflect.Value) ([]byte, error) {
+	if val.Kind() != reflect.Slice {
+		return nil, errors.New("table layout requires a slice")
+	}
+
+	columns, cells, err := e.tableCells(val, false)
+	if err != nil {
+		return nil, err
+	}
+
+	var lines []string
+	header := e.header
+	if header == nil {
+		if len(cells) == 0 {
+			return nil, nil
+		}
+
+		widths := make([]int, len(columns))
+		for i, column := range columns {
+			widths[i] = utf8.RuneCountInString(column)
+			for _, record := range cells {
+				widths[i] = max(widths[i], utf8.RuneCountInString(record[column]))
+			}
+		}
+
+		header = &tableHeader{columns: columns, widths: widths}
+		lines = append(lines, tableLine(columns, widths))
+	} else if err := header.fits(columns, cells); err != nil {
+		return nil, err
+	}
+
+	for _, record := range cells {
+		values := make([]string, len(header.columns))
+		for i, column := range header.columns {
+			values[i] = record[column]
+		}
+		lines = append(lines, tableLine(values, header.widths))
+	}
+	e.header = header
+
+	return []byte(strings.Join(lines, "\n")), nil
+}
+
+// tableHeader is the header row of a table, kept by an Encoder so every
+// table it writes continues the first one
+type tableHeader struct {
+	columns []string
+	widths  []int
+}
+
+// fits checks that rows can be written under the header. Every column must
+// be in it and every cell but those of the last column as wide as it at most,
+// so the cells are read back from the offsets of the header.
+func (h *tableHeader) fits(columns []string, cells []map[string]string) error {
+	for _, column := range columns {
+		if !slices.Contains(h.columns, column) {
+			return errors.New("table column is not in the header: " + column)
+		}
+	}
+
+	for i, column := range h.columns[:len(h.columns)-1] {
+		for _, record := range cells {
+			if utf8.RuneCountInString(record[column]) > h.widths[i] {
+				return errors.New("table cell is wider than its column: " + column)
+			}
+		}
+	}
+
+	return nil
+}
+
+// tableCells walks every element of a slice and returns the columns, every
+// key in the order they first appear, and the cells of each element. Values
+// spanning lines are an error unless multiline is set.
+func (e *encodeState) tableCells(val reflect.Value, multiline bool) ([]string, []map[string]string, error) {
+	var columns []string
+	index := map[string]int{}
+	cells := make([]map[string]string, val.Len())
+	for i := 0; i < val.Len(); i++ {
+		var rows []row
+		if err := e.plainStruct(&rows, val.Index(i), ""); err != nil {
+			return nil, nil, err
+		}
+
+		cells[i] = map[string]string{}
+		for _, r := range rows {
+			if r.key == "" {
+				return nil, nil, errors.New("table layout requires a slice of structs or maps")
+			}
+			if !multiline && strings.ContainsAny(r.value, "\r\n") {
+				return nil, nil, errors.New("table cells cannot span lines: " + r.key)
+			}
+
+			key := e.formatKey(r.key)
+			if !multiline && strings.ContainsAny(key, " \t") {
+				return nil, nil, errors.New("table column names cannot contain spaces: " + key)
+			}
+			if _, ok := index[key]; !ok {
+				index[key] = len(columns)
+				columns = append(columns, key)
+			}
+			cells[i][key] = r.value
+		}
+	}
+
+	return columns, cells, nil
+}
+
+// tableLine pads values to the widths of their columns, two spaces apart
+func tableLine(values []string, widths []int) string {
+	var sb strings.Builder
+	for i, value := range values {
+		sb.WriteString(value)
+		if i < len(values)-1 {
+			sb.WriteString(strings.Repeat(" ", widths[i]-utf8.RuneCountInString(value)+2))
+		}
+	}
+
+	return strings.TrimRight(sb.String(), " ")
+}
+
+// tableColumn is a column name and the rune offset it starts at
+type tableColumn struct {
+	name  string
+	start int
+}
+
+// unmarshalTable reads the rows of a table into a slice of structs or maps
+func (d *decodeState) unmarshalTable(data []byte, v reflect.Value) error {
+	if v.Kind() != reflect.Slice {
+		return errors.New("table layout requires a slice")
+	}
+	elementType := v.Type().Elem()
+	if elementType.Kind() != reflect.Struct && elementType.Kind() != reflect.Map {
+		return errors.New("table layout requires a slice of structs or maps")
+	}
+	v.Set(reflect.Zero(v.Type()))
+
+	var columns []tableColumn
+	for i, text := range strings.Split(string(data), "\n") {
+		line := []rune(strings.TrimRight(text, " \t\r"))
+		if len(line) == 0 {
+			continue
+		}
+
+		// Comments may only come before the header, a row can start with #
+		if columns == nil && isComment(strings.TrimSpace(string(line))) {
+			continue
+		}
+
+		// The first line is the header
+		if columns == nil {
+			columns = tableColumns(line)
+			continue
+		}
+
+		if err := d.checkRecords(i + 1); err != nil {
+			return err
+		}
+
+		newElement := reflect.New(elementType).Elem()
+		seen := map[string]bool{}
+		for j, column := range columns {
+			end := len(line)
+			if j < len(columns)-1 {
+				end = min(columns[j+1].start, len(line))
+			}
+			if column.start >= end {
+				continue
+			}
+
+			value := strings.TrimSpace(string(line[column.start:end]))
+			if value == "" {
+				continue
+			}
+
+			var err error
+			if elementType.Kind() == reflect.Map {
+				err = d.setMapValue(newElement, column.name, value)
+			} else {
+				seen[strings.ToLower(column.name)] = true
+				err = d.setFieldValue(newElement, column.name, value, true)
+			}
+			if err != nil {
+				if err := d.fail(pair{key: column.name, line: i + 1}, err); err != nil {
+					return err
+				}
+				seen[strings.ToLower(column.name)] = false
+			}
+		}
+
+		if elementType.Kind() == reflect.Struct {
+			if err := d.finishStruct(newElement, seen); err != nil {
+				return err
+			}
+		}
+		v.Set(reflect.Append(v, newElement))
+		d.record++
+	}
+
+	return nil
+}
+
+// tableColumns returns the names of a header line and where they start
+func tableColumns(line []rune) []tableColumn {
+	var columns []tableColumn
+	for i := 0; i < len(line); i++ {
+		if line[i] == ' ' || line[i] == '\t' {
+			continue
+		}
+
+		start := i
+		for i < len(line) && line[i] != ' ' && line[i] != '\t' {
+			i++
+		}
+		columns = append(columns, tableColumn{name: string(line[start:i]), start: start})
+	}
+
+	return columns
+}
@@ -0,0 +1,189 @@
+package plain
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type TestTableAddress struct {
+	City string `plain:"city"`
+}
+
+type TestTable struct {
+	Name    string           `plain:"name,required"`
+	Age     int              `plain:"age"`
+	Active  bool             `plain:"active,default=true"`
+	Address TestTableAddress `plain:"address"`
+}
+
+func TestMarshalTable(t *testing.T) {
+	t.Run("Structs", func(t *testing.T) {
+		resp, err := MarshalTable([]TestTable{
+			{Name: "John Doe", Age: 42, Active: true, Address: TestTableAddress{City: "New York"}},
+			{Name: "Jane", Age: 7},
+		})
+		if err != nil {
+			t.Fatalf("Was not expecting an error got %s", err)
+		}
+
+		expected := strings.Join([]string{
+			"name      age  active  address.city",
+			"John Doe  42   true    New York",
+			"Jane      7    false",
+		}, "\n")
+		if string(resp) != expected {
+			t.Fatalf("MarshalTable was expecting %q\n got %q", expected, resp)
+		}
+	})
+
+	t.Run("Maps", func(t *testing.T) {
+		resp, err := MarshalTable([]map[string]any{{"a": 1}, {"a": 2, "b": "x"}})
+		if err != nil {
+			t.Fatalf("Was not expecting an error got %s", err)
+		}
+
+		expected := "a  b\n1\n2  x"
+		if string(resp) != expected {
+			t.Fatalf("MarshalTable was expecting %q\n got %q", expected, resp)
+		}
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		for _, v := range []any{TestTable{}, []string{"a"}, []map[string]string{{"a b": "c"}}, []map[string]string{{"a": "b\nc"}}} {
+			if _, err := MarshalTable(v); err == nil {
+				t.Fatalf("MarshalTable was expecting an error for %v", v)
+			}
+		}
+	})
+}
+
+func TestUnmarshalTable(t *testing.T) {
+	t.Run("Round trip", func(t *testing.T) {
+		value := []TestTable{
+			{Name: "John Doe", Age: 42, Active: true, Address: TestTableAddress{City: "New York"}},
+			{Name: "Jane", Age: 7, Active: true},
+		}
+		data, err := MarshalTable(value)
+		if err != nil {
+			t.Fatalf("Was not expecting an error got %s", err)
+		}
+
+		var result []TestTable
+		if err := UnmarshalTable(data, &result); err != nil {
+			t.Fatalf("Was not expecting an error got %s", err)
+		}
+		if !reflect.DeepEqual(result, value) {
+			t.Fatalf("UnmarshalTable was expecting %+v\n got %+v", value, result)
+		}
+	})
+
+	t.Run("Defaults and validation", func(t *testing.T) {
+		var result []TestTable
+		data := "# people\nname  age\nJohn  1\n      2\n"
+		err := UnmarshalTable([]byte(data), &result)
+
+		var validationErrs ValidationErrors
+		if !errors.As(err, &validationErrs) || len(validationErrs) != 1 || validationErrs[0].Record != 1 {
+			t.Fatalf("Was expecting a required error in record 1 got %v", err)
+		}
+		if len(result) != 2 || !result[0].Active || result[1].Age != 2 {
+			t.Fatalf("UnmarshalTable was expecting two rows with defaults got %+v", result)
+		}
+	})
+
+	t.Run("Decode error", func(t *testing.T) {
+		var result []TestTable
+		err := UnmarshalTable([]byte("name  age\nJohn  x\n"), &result)
+
+		var decodeErr *DecodeError
+		if !errors.As(err, &decodeErr) || decodeErr.Line != 2 || decodeErr.Key != "age" {
+			t.Fatalf("Was expecting a DecodeError for age on line 2 got %v", err)
+		}
+	})
+
+	t.Run("Maps", func(t *testing.T) {
+		var result []map[string]any
+		if err := UnmarshalTable([]byte("a    b.c\nx y  [1, 2]\n"), &result); err != nil {
+			t.Fatalf("Was not expecting an error got %s", err)
+		}
+
+		expected := []map[string]any{{"a": "x y", "b": map[string]any{"c": []any{"1", "2"}}}}
+		if !reflect.DeepEqual(result, expected) {
+			t.Fatalf("UnmarshalTable was expecting %v\n got %v", expected, result)
+		}
+	})
+
+	t.Run("Stream", func(t *testing.T) {
+		var buf bytes.Buffer
+		enc := NewEncoder(&buf)
+		enc.SetLayout(LayoutTable)
+		if err := enc.Encode([]TestTable{{Name: "a", Age: 1, Active: true}}); err != nil {
+			t.Fatalf("Was not expecting an error got %s", err)
+		}
+
+		var result []TestTable
+		dec := NewDecoder(&buf)
+		dec.SetLayout(LayoutTable)
+		if err := dec.Decode(&result); err != nil {
+			t.Fatalf("Was not expecting an error got %s", err)
+		}
+		if len(result) != 1 || result[0].Name != "a" {
+			t.Fatalf("Decoder was expecting one row got %+v", result)
+		}
+	})
+
+	t.Run("Stream of tables", func(t *testing.T) {
+		var buf bytes.Buffer
+		enc := NewEncoder(&buf)
+		enc.SetLayout(LayoutTable)
+		for _, rows := range [][]TestTable{{{Name: "John", Age: 42, Active: true}}, {{Name: "Jane", Age: 7}}} {
+			if err := enc.Encode(rows); err != nil {
+				t.Fatalf("Was not expecting an error got %s", err)
+			}
+		}
+
+		expected := "name  age  active  address.city\nJohn  42   true\nJane  7    false\n"
+		if buf.String() != expected {
+			t.Fatalf("Encode was expecting %q\n got %q", expected, buf.String())
+		}
+
+		var result []TestTable
+		if err := UnmarshalTable(buf.Bytes(), &result); err != nil {
+			t.Fatalf("Was not expecting an error got %s", err)
+		}
+		if len(result) != 2 || result[1].Name != "Jane" {
+			t.Fatalf("UnmarshalTable was expecting two rows got %+v", result)
+		}
+
+		err := enc.Encode([]TestTable{{Name: "Jim Doe"}})
+		if err == nil || err.Error() != "table cell is wider than its column: name" {
+			t.Fatalf("Encode was expecting a cell width error got %v", err)
+		}
+	})
+
+	t.Run("Hash cells", func(t *testing.T) {
+		value := []TestTable{{Name: "#1", Age: 1, Active: true}, {Name: "# two", Age: 2, Active: true}}
+		data, err := MarshalTable(value)
+		if err != nil {
+			t.Fatalf("Was not expecting an error got %s", err)
+		}
+
+		var result []TestTable
+		if err := UnmarshalTable(append([]byte("# people\n"), data...), &result); err != nil {
+			t.Fatalf("Was not expecting an error got %s", err)
+		}
+		if !reflect.DeepEqual(result, value) {
+			t.Fatalf("UnmarshalTable was expecting %+v\n got %+v", value, result)
+		}
+	})
+
+	t.Run("Not a slice", func(t *testing.T) {
+		var result TestTable
+		if err := UnmarshalTable([]byte("name\na"), &result); err == nil {
+			t.Fatal("Was expecting an error for a struct target")
+		}
+	})
+}
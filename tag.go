@@ -0,0 +1,57 @@
+package plain
+
+import (
+	"reflect"
+	"strings"
+)
+
+// tagOptions holds the options that follow the key in a struct tag, such as
+// default=8080. Options without a value map to an empty string.
+type tagOptions map[string]string
+
+// has reports whether the option is set
+func (o tagOptions) has(name string) bool {
+	_, ok := o[name]
+	return ok
+}
+
+// fieldTag returns the key for a struct field, preferring the plain tag and
+// falling back to the form tag.
+func fieldTag(field reflect.StructField) string {
+	name, _ := parseTag(field)
+	return name
+}
+
+// parseTag splits a field tag into its key and options. Commas inside
+// brackets or braces do not split, so default=[a, b] stays one option.
+func parseTag(field reflect.StructField) (string, tagOptions) {
+	tag := field.Tag.Get("plain")
+	if tag == "" {
+		tag = field.Tag.Get("form")
+	}
+
+	var parts []string
+	depth, start := 0, 0
+	for i, r := range tag {
+		switch r {
+		case '[', '{', '(':
+			depth++
+		case ']', '}', ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, tag[start:i])
+				start = i + 1
+			}
+		}
+	}
+	parts = append(parts, tag[start:])
+
+	opts := tagOptions{}
+	for _, part := range parts[1:] {
+		name, value, _ := strings.Cut(part, "=")
+		opts[strings.TrimSpace(name)] = value
+	}
+
+	return strings.TrimSpace(parts[0]), opts
+}
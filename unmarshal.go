@@ -3,9 +3,17 @@ package plain
 import (
 	"bytes"
 	"errors"
+	"io/fs"
 	"reflect"
 	"strconv"
 	"strings"
+	"time"
+	"unicode"
+)
+
+var (
+	durationType = reflect.TypeOf(time.Duration(0))
+	timeType     = reflect.TypeOf(time.Time{})
 )
 
 type Unmarshaler interface {
@@ -14,12 +22,56 @@ type Unmarshaler interface {
 
 // Unmarshal parses the plain text data and fills the provided target variable.
 func Unmarshal(data []byte, v any) error {
+	return newDecodeState(decodeOptions{}).unmarshal(data, v)
+}
+
+// decodeOptions are the settings of a Decoder
+type decodeOptions struct {
+	collectErrors bool
+	dialect       Dialect
+	interpolate   bool
+	lookup        func(string) (string, bool)
+	fsys          fs.FS
+	layout        Layout
+	maxDepth      int // deepest a key may nest, 0 for DefaultMaxDepth
+	limits        Limits
+}
+
+// decodeState holds the options and collected errors of a single decode
+type decodeState struct {
+	decodeOptions
+
+	record         int // index of the record being decoded
+	depth          int // depth of the value whose records are being decoded
+	errs           DecodeErrors
+	validationErrs ValidationErrors
+}
+
+func newDecodeState(opts decodeOptions) *decodeState {
+	if opts.dialect == (Dialect{}) {
+		opts.dialect = DialectDefault
+	}
+	if opts.maxDepth <= 0 {
+		opts.maxDepth = DefaultMaxDepth
+	}
+	if limit := opts.limits.MaxKeyDepth; limit > 0 && limit < opts.maxDepth {
+		opts.maxDepth = limit
+	}
+
+	return &decodeState{decodeOptions: opts}
+}
+
+func (d *decodeState) unmarshal(data []byte, v any) error {
 	// Ensure v is a pointer
 	rv := reflect.ValueOf(v)
 	if rv.Kind() != reflect.Pointer || rv.IsNil() {
 		return errors.New("v must be a non-nil pointer")
 	}
 
+	if err := d.checkInput("", data); err != nil {
+		return err
+	}
+
 	// Dereference the pointer
 	rv = rv.Elem()
 
@@ -28,22 +80,75 @@ func Unmarshal(data []byte, v any) error {
 		return unmarshaler.UnmarshalPlain(data)
 	}
 
-	// Handle basic types (string, int, float64, bool)
-	if isBasicType(rv.Kind()) {
-		return unmarshalBasicType(data, rv)
+	var err error
+	switch {
+	case d.layout == LayoutTable:
+		err = d.unmarshalTable(data, rv)
+	case d.layout == LayoutMarkdown:
+		err = errors.New("markdown layout cannot be decoded")
+	case isBasicType(rv.Kind()):
+		// Handle basic types (string, int, float64, bool)
+		err = unmarshalBasicType(data, rv)
+	case rv.Kind() == reflect.Slice:
+		// Handle slice of any type, replacing what it held
+		rv.Set(reflect.Zero(rv.Type()))
+		err = d.unmarshalRecords(data, rv)
+	case rv.Kind() == reflect.Struct:
+		// Handle struct
+		err = d.unmarshalStruct(data, rv, 1)
+	case rv.Kind() == reflect.Map:
+		// Handle map
+		err = d.unmarshalMap(data, rv, 1)
+	default:
+		err = errors.New("unsupported type for unmarshaling")
+	}
+	if err != nil {
+		return err
 	}
 
-	// Handle slice of any type
-	if rv.Kind() == reflect.Slice {
-		return unmarshalSlice(data, rv)
+	return d.finish()
+}
+
+// fail records an error for the key and line of a pair. It returns nil when
+// errors are collected, so decoding can go on with the next line.
+func (d *decodeState) fail(p pair, err error) error {
+	// A value nested too deep is reported where it is, once
+	if errors.Is(err, ErrMaxDepth) {
+		if decodeErr, ok := err.(*DecodeError); ok {
+			return decodeErr
+		}
 	}
 
-	// Handle struct
-	if rv.Kind() == reflect.Struct {
-		return unmarshalStruct(data, rv)
+	// Exceeding a limit stops decoding
+	var limitErr *LimitError
+	if errors.As(err, &limitErr) {
+		if limitErr.Line == 0 {
+			limitErr.File, limitErr.Line = p.file, p.line
+		}
+		return limitErr
 	}
 
-	return errors.New("unsupported type for unmarshaling")
+	decodeErr := &DecodeError{File: p.file, Line: p.line, Key: p.key, Err: err}
+	if !d.collectErrors {
+		return decodeErr
+	}
+
+	d.errs = append(d.errs, decodeErr)
+	return nil
+}
+
+// finish returns the errors collected over the whole decode
+func (d *decodeState) finish() error {
+	switch {
+	case len(d.errs) > 0 && len(d.validationErrs) > 0:
+		return errors.Join(d.errs, d.validationErrs)
+	case len(d.errs) > 0:
+		return d.errs
+	case len(d.validationErrs) > 0:
+		return d.validationErrs
+	}
+
+	return nil
 }
 
 // unmarshalBasicType handles unmarshaling of basic data types.
@@ -78,44 +183,96 @@ func unmarshalBasicType(data []byte, v reflect.Value) error {
 	return nil
 }
 
-// unmarshalSlice handles unmarshaling of slice types.
-func unmarshalSlice(data []byte, v reflect.Value) error {
+// unmarshalRecords handles unmarshaling of a slice of records separated by
+// the record separator of the dialect.
+func (d *decodeState) unmarshalRecords(data []byte, v reflect.Value) error {
 	elementType := v.Type().Elem()
+	dialect := d.dialect
+
+	// Split the data into separate elements by the record separator, one
+	// at a time so the records limit is checked before the rest is split
+	line := 1
+	separator := []byte(dialect.RecordSeparator)
+	for data != nil {
+		elementData := data
+		if end := bytes.Index(data, separator); end >= 0 {
+			elementData, data = data[:end], data[end+len(separator):]
+		} else {
+			data = nil
+		}
 
-	// Split the data into separate elements by newline
-	elementsData := bytes.Split(data, []byte("\n\n"))
-	for _, elementData := range elementsData {
 		trimmedData := strings.TrimSpace(string(elementData))
+		leading := elementData[:len(elementData)-len(bytes.TrimLeft(elementData, " \t\r\n"))]
+		firstLine := line + bytes.Count(leading, []byte("\n"))
+		line += bytes.Count(elementData, []byte("\n")) + bytes.Count(separator, []byte("\n"))
+
+		if trimmedData == "" || d.onlyComments(trimmedData) {
+			continue // skip empty records, e.g. from trailing newlines
+		}
+
+		if err := d.checkRecords(firstLine); err != nil {
+			return err
+		}
 
 		// Check if the element is in array format
-		if strings.HasPrefix(trimmedData, "[") && strings.HasSuffix(trimmedData, "]") {
-			// Process as an array formatted string
-			arrayContent := trimmedData[1 : len(trimmedData)-1]
-			arrayElements := strings.Split(arrayContent, ",")
-			for _, arrayElement := range arrayElements {
-				trimmedElement := strings.TrimSpace(arrayElement)
-				if err := processElement(trimmedElement, elementType, v); err != nil {
-					return err
-				}
-			}
-		} else {
-			// Process as a single element
-			if err := processElement(trimmedData, elementType, v); err != nil {
+		if (dialect.ListStart != "" || dialect.ListEnd != "") && hasAffixes(trimmedData, dialect.ListStart, dialect.ListEnd) {
+			if err := d.unmarshalSlice([]byte(trimmedData), v); err != nil {
 				return err
 			}
+			continue
+		}
+
+		// Process as a single record
+		if err := d.processElement(trimmedData, elementType, v, firstLine); err != nil {
+			return err
 		}
+		d.record++
 	}
 
 	return nil
 }
 
+// unmarshalSlice handles unmarshaling of slice values.
+func (d *decodeState) unmarshalSlice(data []byte, v reflect.Value) error {
+	elementType := v.Type().Elem()
+	trimmedData := strings.TrimSpace(string(data))
+
+	// The list is a level of its own
+	d.depth++
+	defer func() { d.depth-- }()
+
+	// Check if the element is in array format
+	if arrayContent, ok := d.dialect.isList(trimmedData); ok {
+		// Process as an array formatted string
+		elements, err := d.listElements(arrayContent)
+		if err != nil {
+			return err
+		}
+		for _, arrayElement := range elements {
+			if err := d.processElement(arrayElement, elementType, v, 1); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	// Process as a single element
+	return d.processElement(trimmedData, elementType, v, 1)
+}
+
 // processElement handles the creation and setting of a new element in the slice.
-func processElement(elementData string, elementType reflect.Type, v reflect.Value) error {
+func (d *decodeState) processElement(elementData string, elementType reflect.Type, v reflect.Value, line int) error {
 	newElement := reflect.New(elementType).Elem()
 
 	// Check if element is a struct or a basic type
 	if elementType.Kind() == reflect.Struct {
-		err := unmarshalStruct([]byte(elementData), newElement)
+		err := d.unmarshalStruct([]byte(elementData), newElement, line)
+		if err != nil {
+			return err
+		}
+	} else if elementType.Kind() == reflect.Map {
+		err := d.unmarshalMap([]byte(elementData), newElement, line)
 		if err != nil {
 			return err
 		}
@@ -124,6 +281,11 @@ func processElement(elementData string, elementType reflect.Type, v reflect.Valu
 		if err != nil {
 			return err
 		}
+	} else if elementType == durationType {
+		err := d.setValue(newElement, elementData)
+		if err != nil {
+			return err
+		}
 	} else {
 		return errors.New("unsupported slice element type")
 	}
@@ -132,79 +294,465 @@ func processElement(elementData string, elementType reflect.Type, v reflect.Valu
 	return nil
 }
 
-// unmarshalStruct handles unmarshaling of struct types
-func unmarshalStruct(data []byte, v reflect.Value) error {
+// unmarshalStruct handles unmarshaling of struct types, line is the line
+// number of the first line of data
+func (d *decodeState) unmarshalStruct(data []byte, v reflect.Value, line int) error {
 	if v.Kind() != reflect.Struct {
 		return errors.New("expected a struct type")
 	}
 
-	lines := bytes.Split(data, []byte("\n"))
-	for _, line := range lines {
-		pair := bytes.SplitN(line, []byte(":"), 2)
-		if len(pair) != 2 {
+	seen := map[string]bool{}
+	if err := d.mergeStruct(data, v, line, seen); err != nil {
+		return err
+	}
+
+	return d.finishStruct(v, seen)
+}
+
+// mergeStruct sets the keys of data on a struct, adding them to seen. A key
+// whose value failed to decode is kept in seen as false. The first value of
+// a key replaces the contents of slice fields, unless they are tagged
+// merge=append.
+func (d *decodeState) mergeStruct(data []byte, v reflect.Value, line int, seen map[string]bool) error {
+	pairs, err := d.pairs(data, line)
+	if err != nil {
+		return err
+	}
+
+	// Keys repeated within data add to the slices they set
+	replaced := map[string]bool{}
+	for _, p := range pairs {
+		key := strings.ToLower(p.key)
+		seen[key] = true
+
+		// Handle nested fields indicated by the path separator, records in
+		// list values nesting under the key
+		depth := d.depth
+		d.depth += d.keyDepth(p.key)
+		err := d.setFieldValue(v, p.key, p.value, !replaced[key])
+		d.depth = depth
+		if err != nil {
+			if err := d.fail(p, err); err != nil {
+				return err
+			}
+			seen[key] = false
+		}
+		replaced[key] = true
+	}
+
+	return nil
+}
+
+// pair is a key and value read from a line
+type pair struct {
+	key   string
+	value string
+	file  string // included file, empty for the decoded input
+	line  int
+}
+
+// pairs returns the key/value lines of a record, expanding includes and,
+// when interpolation is enabled, references. Lines that fail are left out
+// when errors are collected.
+func (d *decodeState) pairs(data []byte, line int) ([]pair, error) {
+	pairs, err := d.readPairs("", data, line, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if d.interpolate {
+		return d.expand(pairs)
+	}
+
+	return pairs, nil
+}
+
+// readPairs reads the key/value lines of data from file, splicing in the
+// pairs of included files. stack holds the files being included.
+func (d *decodeState) readPairs(file string, data []byte, line int, stack []string) ([]pair, error) {
+	var pairs []pair
+	for _, l := range d.sourceLines(data, line) {
+		if target, ok := includeTarget(l.text); ok && d.fsys != nil {
+			included, err := d.include(file, target, stack)
+			if _, ok := err.(*DecodeError); ok {
+				return nil, err // already located in the included file
+			}
+			if err != nil {
+				if err := d.fail(pair{key: includeDirective, file: file, line: l.line}, err); err != nil {
+					return nil, err
+				}
+				continue
+			}
+
+			pairs = append(pairs, included...)
+			continue
+		}
+
+		key, value, ok, err := d.splitPair(l.text)
+		if err != nil {
+			if err := d.fail(pair{key: key, file: file, line: l.line}, err); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if !ok {
 			continue // skip invalid lines
 		}
+		if d.depth+d.keyDepth(key) > d.maxDepth {
+			if err := d.checkKeyDepth(file, l.line); err != nil {
+				return nil, err
+			}
+			if err := d.fail(pair{key: key, file: file, line: l.line}, ErrMaxDepth); err != nil {
+				return nil, err
+			}
+			continue
+		}
 
-		key := strings.TrimSpace(string(pair[0]))
-		value := strings.TrimSpace(string(pair[1]))
+		pairs = append(pairs, pair{key: key, value: value, file: file, line: l.line})
+	}
 
-		// Handle nested fields indicated by a dot separator
-		if err := setFieldValue(v, key, value); err != nil {
-			return err
+	return pairs, nil
+}
+
+// finishStruct applies defaults and validation once every key of a record
+// has been set
+func (d *decodeState) finishStruct(v reflect.Value, seen map[string]bool) error {
+	if err := d.applyDefaults(v, "", seen); err != nil {
+		return err
+	}
+
+	errs, err := d.validateStruct(v, "", "", seen)
+	if err != nil {
+		return err
+	}
+	for _, e := range errs {
+		e.Record = d.record
+	}
+	d.validationErrs = append(d.validationErrs, errs...)
+
+	return nil
+}
+
+// applyDefaults sets the default tag option of fields whose key was not in
+// the input and that still hold their zero value.
+func (d *decodeState) applyDefaults(v reflect.Value, parent string, seen map[string]bool) error {
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Field(i)
+		tagValue, opts := parseTag(v.Type().Field(i))
+		if tagValue == "-" || tagValue == "" || !field.CanSet() {
+			continue
+		}
+
+		key := strings.ToLower(tagValue)
+		if parent != "" {
+			key = parent + d.dialect.PathSeparator + key
+		}
+
+		// Nested structs get their own defaults
+		if field.Kind() == reflect.Struct && field.Type() != timeType {
+			if err := d.applyDefaults(field, key, seen); err != nil {
+				return err
+			}
+			continue
+		}
+
+		defaultValue, ok := opts["default"]
+		if _, present := seen[key]; !ok || present || !field.IsZero() {
+			continue
+		}
+
+		if err := d.setValue(field, defaultValue); err != nil {
+			return errors.New("invalid default for " + key + ": " + err.Error())
 		}
 	}
 
 	return nil
 }
 
-// setFieldValue sets the value of a field, handling nested structs.
-func setFieldValue(v reflect.Value, key, value string) error {
-	keys := strings.Split(key, ".")
+// setFieldValue sets the value of a field, handling nested structs. Keys of
+// nested fields are matched against the tag followed by the path separator,
+// so tags may contain the separator themselves. With replace set, slice
+// fields are emptied before the value is added unless tagged merge=append.
+func (d *decodeState) setFieldValue(v reflect.Value, key, value string, replace bool) error {
+	if v.Kind() != reflect.Struct {
+		return errors.New("attempted to navigate into non-struct field")
+	}
 
-	for i, k := range keys {
-		if v.Kind() == reflect.Struct {
-			found := false
-			for j := 0; j < v.NumField(); j++ {
-				field := v.Field(j)
-				fieldType := v.Type().Field(j)
-				tagValue := fieldType.Tag.Get("plain")
-				if tagValue == "" {
-					tagValue = fieldType.Tag.Get("form")
-				}
+	separator := d.dialect.PathSeparator
+	var nestedErr error
+	for j := 0; j < v.NumField(); j++ {
+		field := v.Field(j)
+		tagValue, opts := parseTag(v.Type().Field(j))
 
-				// Check if tag is "-" or empty
-				if tagValue == "-" || tagValue == "" {
-					continue
-				}
+		// Check if tag is "-" or empty
+		if tagValue == "-" || tagValue == "" {
+			continue
+		}
 
-				if strings.EqualFold(tagValue, k) {
-					if i == len(keys)-1 {
-						// Last key, set the value
-						return setValue(field, value)
-					} else if field.Kind() == reflect.Struct {
-						// Nested struct, proceed to the next level
-						v = field
-						found = true
-						break
-					} else {
-						return errors.New("non-struct field found in nested path: " + k)
-					}
+		if strings.EqualFold(tagValue, key) {
+			// Last key, set the value
+			if field.Kind() == reflect.Slice {
+				if err := mergeSlice(field, opts, replace); err != nil {
+					return err
 				}
 			}
-			if !found {
-				// If no matching field is found, ignore and continue
-				return nil
+			return d.setValue(field, value)
+		}
+
+		prefix := len(tagValue) + len(separator)
+		if len(key) <= prefix || !strings.EqualFold(key[:len(tagValue)], tagValue) || key[len(tagValue):prefix] != separator {
+			continue
+		}
+
+		switch {
+		case field.Kind() == reflect.Struct:
+			// Nested struct, proceed to the next level
+			return d.setFieldValue(field, key[prefix:], value, replace)
+		case field.Kind() == reflect.Ptr && field.Type().Elem().Kind() == reflect.Struct:
+			// Pointer to a struct, allocated the first time a key reaches it
+			if field.IsNil() {
+				field.Set(reflect.New(field.Type().Elem()))
+			}
+			return d.setFieldValue(field.Elem(), key[prefix:], value, replace)
+		case field.Kind() == reflect.Map:
+			// Map field, the rest of the key belongs to the map
+			return d.setMapValue(field, key[prefix:], value)
+		case nestedErr == nil:
+			// Another field may still match a longer tag
+			nestedErr = errors.New("non-struct field found in nested path: " + tagValue)
+		}
+	}
+
+	// If no matching field is found, ignore and continue
+	return nestedErr
+}
+
+// unmarshalMap handles unmarshaling of map types, line is the line number
+// of the first line of data
+func (d *decodeState) unmarshalMap(data []byte, v reflect.Value, line int) error {
+	pairs, err := d.pairs(data, line)
+	if err != nil {
+		return err
+	}
+
+	for _, p := range pairs {
+		depth := d.depth
+		d.depth += d.keyDepth(p.key)
+		err := d.setMapValue(v, p.key, p.value)
+		d.depth = depth
+		if err != nil {
+			if err := d.fail(p, err); err != nil {
+				return err
 			}
-		} else {
-			return errors.New("attempted to navigate into non-struct field")
 		}
 	}
 
 	return nil
 }
 
+// setMapValue sets a map entry. Maps of interface values nest dotted keys
+// into further map[string]any values, other maps keep the key as is.
+func (d *decodeState) setMapValue(v reflect.Value, key, value string) error {
+	if !v.CanSet() {
+		return errors.New("cannot set field")
+	}
+	if v.Type().Key().Kind() != reflect.String {
+		return errors.New("map keys must be strings")
+	}
+	if v.IsNil() {
+		v.Set(reflect.MakeMap(v.Type()))
+	}
+
+	elementType := v.Type().Elem()
+	if elementType.Kind() != reflect.Interface {
+		newElement := reflect.New(elementType).Elem()
+		if err := d.setValue(newElement, value); err != nil {
+			return err
+		}
+
+		v.SetMapIndex(reflect.ValueOf(key).Convert(v.Type().Key()), newElement)
+		return nil
+	}
+
+	// Walk down the dotted key, creating nested maps as needed
+	m, ok := v.Interface().(map[string]any)
+	if !ok {
+		return errors.New("unsupported map type")
+	}
+
+	keys := strings.Split(key, d.dialect.PathSeparator)
+	for _, k := range keys[:len(keys)-1] {
+		switch existing := m[k].(type) {
+		case nil:
+			nested := map[string]any{}
+			m[k] = nested
+			m = nested
+		case map[string]any:
+			m = existing
+		default:
+			return errors.New("key is both a value and a parent: " + k)
+		}
+	}
+
+	last := keys[len(keys)-1]
+	if _, ok := m[last].(map[string]any); ok {
+		return errors.New("key is both a value and a parent: " + last)
+	}
+	anyValue, err := d.anyValue(value)
+	if err != nil {
+		return err
+	}
+	m[last] = anyValue
+
+	return nil
+}
+
+// anyValue converts a value for an interface target, lists become []any
+// and everything else is kept as a string. Values are only lists in dialects
+// that wrap lists in tokens.
+func (d *decodeState) anyValue(value string) (any, error) {
+	dialect := d.dialect
+	if dialect.ListStart == "" && dialect.ListEnd == "" {
+		return value, nil
+	}
+
+	arrayContent, ok := dialect.isList(value)
+	if !ok {
+		return value, nil
+	}
+
+	elements, err := d.listElements(arrayContent)
+	if err != nil {
+		return nil, err
+	}
+
+	list := []any{}
+	for _, arrayElement := range elements {
+		list = append(list, arrayElement)
+	}
+
+	return list, nil
+}
+
+// sourceLine is a line of input with its line number
+type sourceLine struct {
+	text string
+	line int
+}
+
+// sourceLines splits data into lines numbered from line, joining continued
+// lines and dropping comments.
+func (d *decodeState) sourceLines(data []byte, line int) []sourceLine {
+	var lines []sourceLine
+	for i, l := range strings.Split(string(data), "\n") {
+		lines = append(lines, sourceLine{text: l, line: line + i})
+	}
+	switch d.dialect.Syntax {
+	case SyntaxPlain:
+		lines = continuedLines(lines)
+	case SyntaxProperties:
+		lines = propertyLines(lines)
+	case SyntaxDotenv:
+		lines = dotenvLines(lines)
+	}
+
+	kept := lines[:0]
+	for _, l := range lines {
+		if !d.isComment(strings.TrimSpace(l.text)) {
+			kept = append(kept, l)
+		}
+	}
+
+	return kept
+}
+
+// isComment checks if a trimmed line is a comment in the dialect
+func (d *decodeState) isComment(line string) bool {
+	if d.dialect.Syntax == SyntaxProperties {
+		return isPropertyComment(line)
+	}
+
+	return isComment(line)
+}
+
+// splitPair splits a line on the first key/value separator of the dialect,
+// a separator of only spaces splits on the first space or tab.
+func (d *decodeState) splitPair(line string) (string, string, bool, error) {
+	switch d.dialect.Syntax {
+	case SyntaxProperties:
+		return splitProperty(line)
+	case SyntaxDotenv:
+		return splitDotenv(line)
+	}
+
+	var key, value string
+	var found bool
+	if separator := strings.TrimSpace(d.dialect.Separator); separator != "" {
+		key, value, found = strings.Cut(line, separator)
+	} else {
+		line = strings.TrimSpace(line)
+		if i := strings.IndexAny(line, " \t"); i >= 0 {
+			key, value, found = line[:i], line[i+1:], true
+		}
+	}
+
+	return strings.TrimSpace(key), strings.TrimSpace(value), found, nil
+}
+
+// listElements splits the content of a list on the list separator of the
+// dialect, leaving the separators of nested lists in their element. Empty
+// content is an empty list.
+func (d *decodeState) listElements(content string) ([]string, error) {
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return nil, nil
+	}
+
+	separator := strings.TrimSpace(d.dialect.ListSeparator)
+	var elements []string
+	for more := true; more; {
+		var element string
+		element, content, more = d.nextElement(content, separator)
+		element = strings.TrimSpace(element)
+		if separator == "" && element == "" {
+			continue // runs of spaces separate a single time
+		}
+
+		if err := d.checkListElements(len(elements) + 1); err != nil {
+			return nil, err
+		}
+		elements = append(elements, element)
+	}
+
+	return elements, nil
+}
+
+// nextElement returns the first element of the content of a list and the
+// content after its separator, more being false for the last element. An
+// empty separator splits on spaces.
+func (d *decodeState) nextElement(content, separator string) (element, rest string, more bool) {
+	start, end := d.dialect.ListStart, d.dialect.ListEnd
+	nested := 0
+	for i := 0; i < len(content); i++ {
+		switch {
+		case start != "" && strings.HasPrefix(content[i:], start):
+			nested++
+		case nested > 0 && end != "" && strings.HasPrefix(content[i:], end):
+			nested--
+		case nested > 0:
+			// Separators of a nested list are part of the element
+		case separator == "" && unicode.IsSpace(rune(content[i])):
+			return content[:i], content[i+1:], true
+		case separator != "" && strings.HasPrefix(content[i:], separator):
+			return content[:i], content[i+len(separator):], true
+		}
+	}
+
+	return content, "", false
+}
+
 // setValue sets the field with the provided value, handling type conversion.
-func setValue(field reflect.Value, value string) error {
+func (d *decodeState) setValue(field reflect.Value, value string) error {
 	if !field.CanSet() {
 		return errors.New("cannot set field")
 	}
@@ -213,6 +761,16 @@ func setValue(field reflect.Value, value string) error {
 	case reflect.String:
 		field.SetString(value)
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		// Durations are written as 1h30m by Marshal
+		if field.Type() == durationType {
+			durationValue, err := time.ParseDuration(value)
+			if err != nil {
+				return err
+			}
+			field.SetInt(int64(durationValue))
+			break
+		}
+
 		if intValue, err := strconv.ParseInt(value, 10, 64); err == nil {
 			field.SetInt(intValue)
 		} else {
@@ -231,7 +789,29 @@ func setValue(field reflect.Value, value string) error {
 			return err
 		}
 	case reflect.Slice:
-		return unmarshalSlice([]byte(value), field)
+		return d.unmarshalSlice([]byte(value), field)
+	case reflect.Ptr:
+		// Marshal writes nil pointers as <nil>
+		if value == "<nil>" {
+			field.Set(reflect.Zero(field.Type()))
+			break
+		}
+
+		elem := reflect.New(field.Type().Elem())
+		if err := d.setValue(elem.Elem(), value); err != nil {
+			return err
+		}
+		field.Set(elem)
+	case reflect.Struct:
+		if field.Type() != timeType {
+			return errors.New("unsupported field type")
+		}
+
+		timeValue, err := parseTime(value)
+		if err != nil {
+			return err
+		}
+		field.Set(reflect.ValueOf(timeValue))
 	default:
 		return errors.New("unsupported field type")
 	}
@@ -239,6 +819,31 @@ func setValue(field reflect.Value, value string) error {
 	return nil
 }
 
+// parseTime parses a time written as RFC 3339 or the way Marshal writes it
+func parseTime(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return t, nil
+	}
+
+	// Drop the monotonic clock reading a time.Now value is printed with
+	if i := strings.Index(value, " m="); i >= 0 {
+		value = value[:i]
+	}
+
+	return time.Parse("2006-01-02 15:04:05.999999999 -0700 MST", value)
+}
+
+// onlyComments checks if every line of a record is a comment.
+func (d *decodeState) onlyComments(data string) bool {
+	for _, line := range strings.Split(data, "\n") {
+		if !d.isComment(strings.TrimSpace(line)) {
+			return false
+		}
+	}
+
+	return true
+}
+
 // isBasicType checks if the provided kind is a basic type.
 func isBasicType(kind reflect.Kind) bool {
 	switch kind {
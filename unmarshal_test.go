@@ -260,3 +260,210 @@ func TestUnmarshaler(t *testing.T) {
 		}
 	})
 }
+
+func TestUnmarshalMap(t *testing.T) {
+	t.Run("Unmarshal into map", func(t *testing.T) {
+		data := []byte("name: John Doe\nsub.name: Jane Doe\nsub.tags: [a, b]\nempty: []")
+		var result map[string]any
+		err := Unmarshal(data, &result)
+		if err != nil {
+			t.Fatalf("Failed to unmarshal into map: %v", err)
+		}
+		expected := map[string]any{
+			"name":  "John Doe",
+			"sub":   map[string]any{"name": "Jane Doe", "tags": []any{"a", "b"}},
+			"empty": []any{},
+		}
+		if !reflect.DeepEqual(result, expected) {
+			t.Errorf("Unmarshal into map: got %v, want %v", result, expected)
+		}
+	})
+
+	t.Run("Unmarshal into typed map", func(t *testing.T) {
+		data := []byte("a: 1\nb.c: 2")
+		var result map[string]int
+		err := Unmarshal(data, &result)
+		if err != nil {
+			t.Fatalf("Failed to unmarshal into typed map: %v", err)
+		}
+		expected := map[string]int{"a": 1, "b.c": 2}
+		if !reflect.DeepEqual(result, expected) {
+			t.Errorf("Unmarshal into typed map: got %v, want %v", result, expected)
+		}
+	})
+
+	t.Run("Unmarshal into slice of maps", func(t *testing.T) {
+		data := []byte("name: John Doe\n\nname: Jane Doe\n\n")
+		var result []map[string]any
+		err := Unmarshal(data, &result)
+		if err != nil {
+			t.Fatalf("Failed to unmarshal into slice of maps: %v", err)
+		}
+		expected := []map[string]any{{"name": "John Doe"}, {"name": "Jane Doe"}}
+		if !reflect.DeepEqual(result, expected) {
+			t.Errorf("Unmarshal into slice of maps: got %v, want %v", result, expected)
+		}
+	})
+
+	t.Run("Unmarshal into struct with map", func(t *testing.T) {
+		type TestMapStruct struct {
+			Name   string            `plain:"name"`
+			Labels map[string]string `plain:"labels"`
+		}
+
+		data := []byte("name: John Doe\nlabels.env: prod\nlabels.app: api")
+		var result TestMapStruct
+		err := Unmarshal(data, &result)
+		if err != nil {
+			t.Fatalf("Failed to unmarshal into struct with map: %v", err)
+		}
+		expected := TestMapStruct{"John Doe", map[string]string{"env": "prod", "app": "api"}}
+		if !reflect.DeepEqual(result, expected) {
+			t.Errorf("Unmarshal into struct with map: got %v, want %v", result, expected)
+		}
+	})
+
+	t.Run("Conflicting keys", func(t *testing.T) {
+		data := []byte("sub: value\nsub.name: Jane Doe")
+		var result map[string]any
+		if err := Unmarshal(data, &result); err == nil {
+			t.Fatal("Was expecting an error for a key that is both a value and a parent")
+		}
+	})
+}
+
+func TestUnmarshalComments(t *testing.T) {
+	data := []byte("# people\n\n# first\nname: John Doe\n# age: 99\nage: 30\n\n# trailing\n")
+	var result []TestData
+	err := Unmarshal(data, &result)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal with comments: %v", err)
+	}
+	expected := []TestData{{Name: "John Doe", Age: 30}}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Unmarshal with comments: got %v, want %v", result, expected)
+	}
+}
+
+type TestDefaultsSub struct {
+	Host string `plain:"host,default=localhost"`
+	Port int    `plain:"port,default=8080"`
+}
+
+type TestDefaults struct {
+	Name    string          `plain:"name,default=server"`
+	Debug   bool            `plain:"debug,default=true"`
+	Timeout time.Duration   `plain:"timeout,default=1m30s"`
+	Tags    []string        `plain:"tags,default=[a, b]"`
+	Retries []time.Duration `plain:"retries,default=[1s, 2s]"`
+	Sub     TestDefaultsSub `plain:"sub"`
+}
+
+func TestUnmarshalDefaults(t *testing.T) {
+	t.Run("Missing keys", func(t *testing.T) {
+		var result TestDefaults
+		err := Unmarshal([]byte("sub.port: 9090"), &result)
+		if err != nil {
+			t.Fatalf("Failed to unmarshal defaults: %v", err)
+		}
+		expected := TestDefaults{
+			Name:    "server",
+			Debug:   true,
+			Timeout: 90 * time.Second,
+			Tags:    []string{"a", "b"},
+			Retries: []time.Duration{time.Second, 2 * time.Second},
+			Sub:     TestDefaultsSub{Host: "localhost", Port: 9090},
+		}
+		if !reflect.DeepEqual(result, expected) {
+			t.Errorf("Unmarshal defaults: got %+v, want %+v", result, expected)
+		}
+	})
+
+	t.Run("Present keys", func(t *testing.T) {
+		var result TestDefaults
+		err := Unmarshal([]byte("name: api\ndebug: false\ntimeout: 5s\ntags: [c]"), &result)
+		if err != nil {
+			t.Fatalf("Failed to unmarshal defaults: %v", err)
+		}
+		if result.Name != "api" || result.Debug || result.Timeout != 5*time.Second || !reflect.DeepEqual(result.Tags, []string{"c"}) {
+			t.Errorf("Unmarshal defaults should not replace present keys, got %+v", result)
+		}
+	})
+
+	t.Run("Existing values", func(t *testing.T) {
+		result := TestDefaults{Name: "existing"}
+		err := Unmarshal([]byte(""), &result)
+		if err != nil {
+			t.Fatalf("Failed to unmarshal defaults: %v", err)
+		}
+		if result.Name != "existing" {
+			t.Errorf("Unmarshal defaults should not replace existing values, got %s", result.Name)
+		}
+	})
+
+	t.Run("Slice of records", func(t *testing.T) {
+		var result []TestDefaultsSub
+		err := Unmarshal([]byte("host: one\n\nport: 1"), &result)
+		if err != nil {
+			t.Fatalf("Failed to unmarshal defaults: %v", err)
+		}
+		expected := []TestDefaultsSub{{Host: "one", Port: 8080}, {Host: "localhost", Port: 1}}
+		if !reflect.DeepEqual(result, expected) {
+			t.Errorf("Unmarshal defaults: got %+v, want %+v", result, expected)
+		}
+	})
+
+	t.Run("Invalid default", func(t *testing.T) {
+		var result struct {
+			Port int `plain:"port,default=abc"`
+		}
+		if err := Unmarshal([]byte(""), &result); err == nil {
+			t.Fatal("Was expecting an error for an invalid default")
+		}
+	})
+}
+
+type TestTimes struct {
+	Created time.Time `plain:"created"`
+	Updated time.Time `plain:"updated"`
+}
+
+func TestUnmarshalTime(t *testing.T) {
+	t.Run("Layouts", func(t *testing.T) {
+		data := "created: 2024-05-01T10:30:00.5Z\nupdated: 2024-05-02 08:00:00 +0000 UTC\n"
+
+		var result TestTimes
+		if err := Unmarshal([]byte(data), &result); err != nil {
+			t.Fatalf("Was not expecting an error got %s", err)
+		}
+
+		created := time.Date(2024, 5, 1, 10, 30, 0, 5e8, time.UTC)
+		updated := time.Date(2024, 5, 2, 8, 0, 0, 0, time.UTC)
+		if !result.Created.Equal(created) || !result.Updated.Equal(updated) {
+			t.Fatalf("Unmarshal got %+v", result)
+		}
+	})
+
+	t.Run("Round trip", func(t *testing.T) {
+		now := time.Now()
+		data, err := Marshal(TestTimes{Created: now})
+		if err != nil {
+			t.Fatalf("Was not expecting an error got %s", err)
+		}
+
+		var result TestTimes
+		if err := Unmarshal(data, &result); err != nil {
+			t.Fatalf("Was not expecting an error got %s", err)
+		}
+		if !result.Created.Equal(now) {
+			t.Fatalf("Unmarshal was expecting %s\n got %s", now, result.Created)
+		}
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		var result TestTimes
+		if err := Unmarshal([]byte("created: yesterday\n"), &result); err == nil {
+			t.Fatal("Was expecting an error")
+		}
+	})
+}
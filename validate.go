@@ -0,0 +1,238 @@
+package plain
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// A ValidationError describes a field that failed one of its validation tag
+// options: required, min=, max=, len=, oneof= or regex=.
+type ValidationError struct {
+	Record int    // index of the record, 0 when decoding a single struct
+	Key    string // full dotted key of the field
+	Rule   string // the failing tag option, such as required or min=3
+	Msg    string // description of the failure
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("record %d: %s: %s", e.Record, e.Key, e.Msg)
+}
+
+// ValidationErrors lists every field that failed validation, across all
+// decoded records. Unmarshal still fills every field before returning it.
+type ValidationErrors []*ValidationError
+
+func (e ValidationErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+
+	return strings.Join(messages, "\n")
+}
+
+// Unwrap returns the individual errors for errors.Is and errors.As.
+func (e ValidationErrors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, err := range e {
+		errs[i] = err
+	}
+
+	return errs
+}
+
+// validateStruct checks the validation tag options of every field. Fields
+// whose key was not in the input and that hold their zero value are only
+// checked for required, and fields whose value failed to decode are not
+// checked.
+func (d *decodeState) validateStruct(v reflect.Value, parent, seenParent string, seen map[string]bool) (ValidationErrors, error) {
+	var errs ValidationErrors
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Field(i)
+		tagValue, opts := parseTag(v.Type().Field(i))
+		if tagValue == "-" || tagValue == "" || !field.CanInterface() {
+			continue
+		}
+
+		key, seenKey := tagValue, strings.ToLower(tagValue)
+		if parent != "" {
+			key = parent + "." + key
+			seenKey = seenParent + d.dialect.PathSeparator + seenKey
+		}
+
+		decoded, present := seen[seenKey]
+		failed := present && !decoded
+		if field.Kind() == reflect.Struct && field.Type() != timeType {
+			for k := range seen {
+				if strings.HasPrefix(k, seenKey+d.dialect.PathSeparator) {
+					present = true
+					break
+				}
+			}
+
+			nestedErrs, err := d.validateStruct(field, key, seenKey, seen)
+			if err != nil {
+				return nil, err
+			}
+			errs = append(errs, nestedErrs...)
+		}
+
+		if !present && field.IsZero() {
+			if opts.has("required") {
+				errs = append(errs, &ValidationError{Key: key, Rule: "required", Msg: "is required"})
+			}
+			continue
+		}
+
+		// The decode error is already reported
+		if failed {
+			continue
+		}
+
+		for _, rule := range []string{"min", "max", "len", "oneof", "regex"} {
+			limit, ok := opts[rule]
+			if !ok {
+				continue
+			}
+
+			msg, err := d.checkRule(field, rule, limit)
+			if err != nil {
+				return nil, errors.New("invalid " + rule + " option for " + key + ": " + err.Error())
+			}
+			if msg != "" {
+				errs = append(errs, &ValidationError{Key: key, Rule: rule + "=" + limit, Msg: msg})
+			}
+		}
+	}
+
+	return errs, nil
+}
+
+// checkRule returns a failure message, or an error if the rule does not
+// apply to the field
+func (d *decodeState) checkRule(field reflect.Value, rule, limit string) (string, error) {
+	switch rule {
+	case "min", "max":
+		cmp, length, err := d.compareLimit(field, limit)
+		if err != nil {
+			return "", err
+		}
+
+		subject := "must be"
+		if length {
+			subject = "length must be"
+		}
+		if rule == "min" && cmp < 0 {
+			return subject + " at least " + limit, nil
+		}
+		if rule == "max" && cmp > 0 {
+			return subject + " at most " + limit, nil
+		}
+	case "len":
+		n, err := strconv.Atoi(limit)
+		if err != nil {
+			return "", err
+		}
+
+		length, ok := valueLength(field)
+		if !ok {
+			return "", errors.New("len only applies to strings, slices and maps")
+		}
+		if length != n {
+			return "length must be " + limit, nil
+		}
+	case "oneof":
+		allowed := strings.Split(limit, "|")
+		for _, value := range elementStrings(field) {
+			if !slices.Contains(allowed, value) {
+				return "must be one of " + strings.Join(allowed, ", "), nil
+			}
+		}
+	case "regex":
+		re, err := regexp.Compile(limit)
+		if err != nil {
+			return "", err
+		}
+
+		for _, value := range elementStrings(field) {
+			if !re.MatchString(value) {
+				return "must match " + limit, nil
+			}
+		}
+	}
+
+	return "", nil
+}
+
+// compareLimit compares a number to limit, or the length of strings, slices
+// and maps. Limits are parsed like values of the field, so durations take
+// limits such as 1s.
+func (d *decodeState) compareLimit(field reflect.Value, limit string) (int, bool, error) {
+	if length, ok := valueLength(field); ok {
+		n, err := strconv.Atoi(limit)
+		if err != nil {
+			return 0, true, err
+		}
+
+		return compare(float64(length), float64(n)), true, nil
+	}
+
+	parsed := reflect.New(field.Type()).Elem()
+	if err := d.setValue(parsed, limit); err != nil {
+		return 0, false, err
+	}
+
+	switch field.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return compare(float64(field.Int()), float64(parsed.Int())), false, nil
+	case reflect.Float32, reflect.Float64:
+		return compare(field.Float(), parsed.Float()), false, nil
+	}
+
+	return 0, false, errors.New("only applies to numbers, strings, slices and maps")
+}
+
+func compare(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+
+	return 0
+}
+
+// valueLength returns the length of strings in characters and of slices and
+// maps in elements
+func valueLength(field reflect.Value) (int, bool) {
+	switch field.Kind() {
+	case reflect.String:
+		return utf8.RuneCountInString(field.String()), true
+	case reflect.Slice, reflect.Map:
+		return field.Len(), true
+	}
+
+	return 0, false
+}
+
+// elementStrings returns the elements of a slice, or the value itself, as
+// Marshal would write them
+func elementStrings(field reflect.Value) []string {
+	if field.Kind() != reflect.Slice {
+		return []string{fmt.Sprintf("%v", field.Interface())}
+	}
+
+	values := make([]string, field.Len())
+	for i := range values {
+		values[i] = fmt.Sprintf("%v", field.Index(i).Interface())
+	}
+
+	return values
+}
@@ -0,0 +1,138 @@
+package plain
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type TestValidateSub struct {
+	City string `plain:"city,required"`
+	Zip  string `plain:"zip,len=5,regex=^[0-9]+$"`
+}
+
+type TestValidate struct {
+	Name    string          `plain:"name,required,min=2,max=10"`
+	Age     int             `plain:"age,min=18,max=99"`
+	Role    string          `plain:"role,oneof=admin|user"`
+	Tags    []string        `plain:"tags,max=2,oneof=a|b|c"`
+	Timeout time.Duration   `plain:"timeout,max=1m"`
+	Address TestValidateSub `plain:"address"`
+}
+
+func TestUnmarshalValidation(t *testing.T) {
+	t.Run("Valid", func(t *testing.T) {
+		var result TestValidate
+		data := []byte("name: John\nage: 42\nrole: admin\ntags: [a, b]\ntimeout: 30s\naddress.city: Boston\naddress.zip: 02101")
+		if err := Unmarshal(data, &result); err != nil {
+			t.Fatalf("Was not expecting an error got %s", err)
+		}
+	})
+
+	t.Run("Optional fields", func(t *testing.T) {
+		var result TestValidate
+		if err := Unmarshal([]byte("name: John\naddress.city: Boston"), &result); err != nil {
+			t.Fatalf("Was not expecting an error got %s", err)
+		}
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		var result TestValidate
+		data := []byte("age: 12\nrole: root\ntags: [a, d, c]\ntimeout: 2m\naddress.zip: 12ab5")
+		err := Unmarshal(data, &result)
+
+		var errs ValidationErrors
+		if !errors.As(err, &errs) {
+			t.Fatalf("Was expecting ValidationErrors got %v", err)
+		}
+
+		var got []string
+		for _, e := range errs {
+			got = append(got, e.Key+" "+e.Rule)
+		}
+		expected := []string{
+			"name required",
+			"age min=18",
+			"role oneof=admin|user",
+			"tags max=2",
+			"tags oneof=a|b|c",
+			"timeout max=1m",
+			"address.city required",
+			"address.zip regex=^[0-9]+$",
+		}
+		if !reflect.DeepEqual(got, expected) {
+			t.Fatalf("Validation was expecting %v\n got %v", expected, got)
+		}
+
+		// Fields are still filled
+		if result.Age != 12 || result.Role != "root" {
+			t.Fatalf("Validation should still fill fields, got %+v", result)
+		}
+
+		var single *ValidationError
+		if !errors.As(err, &single) || single.Key != "name" {
+			t.Fatalf("Was expecting errors.As to find the first ValidationError got %v", single)
+		}
+	})
+
+	t.Run("Slice of records", func(t *testing.T) {
+		var result []TestValidateSub
+		data := []byte("city: Boston\nzip: 02101\n\nzip: 123\n\ncity: Chicago")
+		err := Unmarshal(data, &result)
+
+		var errs ValidationErrors
+		if !errors.As(err, &errs) {
+			t.Fatalf("Was expecting ValidationErrors got %v", err)
+		}
+
+		expected := "record 1: city: is required\nrecord 1: zip: length must be 5"
+		if errs.Error() != expected {
+			t.Fatalf("Validation was expecting %q\n got %q", expected, errs.Error())
+		}
+		if len(result) != 3 {
+			t.Fatalf("Validation should keep every record, got %d", len(result))
+		}
+	})
+
+	t.Run("Decode errors", func(t *testing.T) {
+		dec := NewDecoder(strings.NewReader("name: John\nage: x\naddress.city: Boston\n"))
+		dec.CollectErrors()
+
+		var result TestValidate
+		err := dec.Decode(&result)
+
+		var decodeErrs DecodeErrors
+		if !errors.As(err, &decodeErrs) || len(decodeErrs) != 1 || decodeErrs[0].Key != "age" {
+			t.Fatalf("Was expecting a decode error for age got %v", err)
+		}
+		var errs ValidationErrors
+		if errors.As(err, &errs) {
+			t.Fatalf("Was not expecting validation errors got %v", errs)
+		}
+	})
+
+	t.Run("Values", func(t *testing.T) {
+		var result TestValidateSub
+		err := FromValues(url.Values{"zip": {"02101"}}, &result)
+
+		var errs ValidationErrors
+		if !errors.As(err, &errs) || len(errs) != 1 || errs[0].Key != "city" {
+			t.Fatalf("Was expecting a required error for city got %v", err)
+		}
+	})
+
+	t.Run("Invalid rule", func(t *testing.T) {
+		var result struct {
+			Active bool `plain:"active,len=2"`
+		}
+		err := Unmarshal([]byte("active: true"), &result)
+
+		var errs ValidationErrors
+		if err == nil || errors.As(err, &errs) {
+			t.Fatalf("Was expecting a tag error got %v", err)
+		}
+	})
+}
@@ -0,0 +1,171 @@
+package plain
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ToValues converts a struct into url.Values using the same keys Marshal
+// would write. Slice fields produce one value per element, pointers are
+// followed and nil pointers are left out.
+func ToValues(v any) (url.Values, error) {
+	val := reflect.ValueOf(v)
+	if val.Kind() == reflect.Ptr {
+		val = val.Elem()
+	}
+	if val.Kind() != reflect.Struct {
+		return nil, errors.New("v must be a struct or a pointer to a struct")
+	}
+
+	values := url.Values{}
+	if err := valuesStruct(values, val, ""); err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
+
+func valuesStruct(values url.Values, val reflect.Value, parent string) error {
+	typ := val.Type()
+
+	for i := 0; i < val.NumField(); i++ {
+		field := typ.Field(i)
+		tag := fieldTag(field)
+		if tag == "-" || tag == "" {
+			continue
+		}
+
+		// Check if the field is exported
+		if !val.Field(i).CanInterface() {
+			continue
+		}
+
+		fieldName := tag
+		if parent != "" {
+			fieldName = parent + "." + tag
+		}
+
+		// Check if the field adhears to the Marshaler interface, keyed by its
+		// tag the way Marshal writes it
+		if m, ok := val.Field(i).Interface().(Marshaler); ok {
+			marshaled, err := m.MarshalPlain()
+			if err != nil {
+				return err
+			}
+
+			values.Add(tag, string(marshaled))
+			continue
+		}
+
+		if err := valuesField(values, fieldName, val.Field(i)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// valuesField adds the values of a field at key, following pointers and
+// flattening structs and maps into dotted keys. Nil pointers add nothing.
+func valuesField(values url.Values, key string, val reflect.Value) error {
+	val, ok := indirectValue(val)
+	if !ok {
+		return nil
+	}
+
+	switch val.Kind() {
+	case reflect.Struct:
+		// Check if the struct is a time.Time
+		if t, ok := val.Interface().(time.Time); ok {
+			values.Add(key, fmt.Sprintf("%v", t))
+			return nil
+		}
+
+		return valuesStruct(values, val, key)
+	case reflect.Map:
+		// Map keys are sorted the way Marshal writes them
+		keys := val.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+		})
+		for _, k := range keys {
+			if err := valuesField(values, key+"."+fmt.Sprint(k.Interface()), val.MapIndex(k)); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice:
+		// Each element becomes its own value, the way repeated form fields are sent
+		for j := 0; j < val.Len(); j++ {
+			elem, ok := indirectValue(val.Index(j))
+			if !ok {
+				continue
+			}
+			if elem.Kind() == reflect.Struct || elem.Kind() == reflect.Slice || elem.Kind() == reflect.Map {
+				return errors.New("unsupported slice element type for url values: " + key)
+			}
+
+			values.Add(key, fmt.Sprintf("%v", elem.Interface()))
+		}
+	default:
+		values.Add(key, fmt.Sprintf("%v", val.Interface()))
+	}
+
+	return nil
+}
+
+// indirectValue follows pointers and interfaces, reporting false when one
+// of them is nil
+func indirectValue(val reflect.Value) (reflect.Value, bool) {
+	for val.Kind() == reflect.Ptr || val.Kind() == reflect.Interface {
+		if val.IsNil() {
+			return val, false
+		}
+		val = val.Elem()
+	}
+
+	return val, true
+}
+
+// FromValues fills the struct pointed to by v from url.Values, matching keys
+// the same way Unmarshal does. Every value of a key is appended to slice
+// fields, for other fields the last value wins.
+func FromValues(values url.Values, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return errors.New("v must be a non-nil pointer")
+	}
+
+	rv = rv.Elem()
+	if rv.Kind() != reflect.Struct {
+		return errors.New("v must point to a struct")
+	}
+
+	// Sort keys so repeated runs fill and fail in the same order
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	d := newDecodeState(decodeOptions{})
+	seen := map[string]bool{}
+	for _, key := range keys {
+		for i, value := range values[key] {
+			if err := d.setFieldValue(rv, key, strings.TrimSpace(value), i == 0); err != nil {
+				return err
+			}
+		}
+		seen[strings.ToLower(key)] = true
+	}
+
+	if err := d.finishStruct(rv, seen); err != nil {
+		return err
+	}
+
+	return d.finish()
+}
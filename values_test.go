@@ -0,0 +1,211 @@
+package plain
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+type TestValues struct {
+	Name   string   `form:"name"`
+	Age    int      `plain:"age"`
+	Active bool     `plain:"active"`
+	Tags   []string `plain:"tags"`
+	Skip   string   `plain:"-"`
+	Sub    TestData `plain:"sub"`
+}
+
+func TestToValues(t *testing.T) {
+	t.Run("Struct", func(t *testing.T) {
+		values, err := ToValues(TestValues{
+			Name:   "test",
+			Age:    35,
+			Active: true,
+			Tags:   []string{"a", "b"},
+			Skip:   "skip",
+			Sub:    TestData{Name: "sub", Age: 10, Balance: 1.5},
+		})
+		if err != nil {
+			t.Fatalf("Was not expecting an error got %s", err)
+		}
+
+		expected := url.Values{
+			"name":        {"test"},
+			"age":         {"35"},
+			"active":      {"true"},
+			"tags":        {"a", "b"},
+			"sub.name":    {"sub"},
+			"sub.age":     {"10"},
+			"sub.active":  {"false"},
+			"sub.balance": {"1.5"},
+		}
+		if !reflect.DeepEqual(values, expected) {
+			t.Fatalf("ToValues was expecting %v\n got %v", expected, values)
+		}
+	})
+
+	t.Run("Pointer", func(t *testing.T) {
+		values, err := ToValues(&TestData{Name: "test"})
+		if err != nil {
+			t.Fatalf("Was not expecting an error got %s", err)
+		}
+
+		if values.Get("name") != "test" {
+			t.Fatalf("ToValues pointer was expecting test got %s", values.Get("name"))
+		}
+	})
+
+	t.Run("Marshaler", func(t *testing.T) {
+		type TestValuesMarshaler struct {
+			Name TestMarshalerStructMultiple `plain:"name"`
+		}
+
+		values, err := ToValues(TestValuesMarshaler{Name: TestMarshalerStructMultiple{Name: "test", Age: 35}})
+		if err != nil {
+			t.Fatalf("Was not expecting an error got %s", err)
+		}
+
+		expected := "name: test - age: 35"
+		if values.Get("name") != expected {
+			t.Fatalf("ToValues marshaler was expecting %s got %s", expected, values.Get("name"))
+		}
+	})
+
+	t.Run("Pointers and maps", func(t *testing.T) {
+		type TestValuesPointers struct {
+			Name  *string           `plain:"name"`
+			Nil   *string           `plain:"nil"`
+			Sub   *TestData         `plain:"sub"`
+			Map   map[string]string `plain:"m"`
+			Ptrs  []*int            `plain:"ptrs"`
+			Inner struct {
+				In TestMarshalerString `plain:"in"`
+			} `plain:"inner"`
+		}
+
+		name, one := "x", 1
+		value := TestValuesPointers{Name: &name, Sub: &TestData{Name: "NY"}, Map: map[string]string{"b": "c", "a": "b"}, Ptrs: []*int{&one, nil}}
+		value.Inner.In = "marshaled"
+		values, err := ToValues(value)
+		if err != nil {
+			t.Fatalf("Was not expecting an error got %s", err)
+		}
+
+		expected := url.Values{
+			"name":        {"x"},
+			"sub.name":    {"NY"},
+			"sub.age":     {"0"},
+			"sub.active":  {"false"},
+			"sub.balance": {"0"},
+			"m.a":         {"b"},
+			"m.b":         {"c"},
+			"ptrs":        {"1"},
+			"in":          {"marshaled"},
+		}
+		if !reflect.DeepEqual(values, expected) {
+			t.Fatalf("ToValues was expecting %v\n got %v", expected, values)
+		}
+
+	})
+
+	t.Run("Not a struct", func(t *testing.T) {
+		_, err := ToValues("test")
+		if err == nil {
+			t.Fatal("Was expecting an error for a non struct value")
+		}
+	})
+}
+
+func TestFromValues(t *testing.T) {
+	t.Run("Struct", func(t *testing.T) {
+		values := url.Values{
+			"name":        {"test"},
+			"age":         {"35"},
+			"active":      {"true"},
+			"tags":        {"a", "b"},
+			"sub.name":    {"sub"},
+			"sub.balance": {"1.5"},
+			"unknown":     {"ignored"},
+		}
+
+		var result TestValues
+		if err := FromValues(values, &result); err != nil {
+			t.Fatalf("Failed to read values: %v", err)
+		}
+
+		expected := TestValues{
+			Name:   "test",
+			Age:    35,
+			Active: true,
+			Tags:   []string{"a", "b"},
+			Sub:    TestData{Name: "sub", Balance: 1.5},
+		}
+		if !reflect.DeepEqual(result, expected) {
+			t.Errorf("FromValues: got %+v, want %+v", result, expected)
+		}
+	})
+
+	t.Run("List value", func(t *testing.T) {
+		var result TestValues
+		if err := FromValues(url.Values{"tags": {"[a, b]"}}, &result); err != nil {
+			t.Fatalf("Failed to read values: %v", err)
+		}
+
+		expected := []string{"a", "b"}
+		if !reflect.DeepEqual(result.Tags, expected) {
+			t.Errorf("FromValues list: got %v, want %v", result.Tags, expected)
+		}
+	})
+
+	t.Run("Round trip", func(t *testing.T) {
+		input := TestValues{Name: "test", Age: 35, Tags: []string{"a"}, Sub: TestData{Name: "sub", Active: true}}
+		values, err := ToValues(input)
+		if err != nil {
+			t.Fatalf("Was not expecting an error got %s", err)
+		}
+
+		var result TestValues
+		if err := FromValues(values, &result); err != nil {
+			t.Fatalf("Failed to read values: %v", err)
+		}
+		if !reflect.DeepEqual(result, input) {
+			t.Errorf("Round trip: got %+v, want %+v", result, input)
+		}
+	})
+
+	t.Run("Round trip pointers and maps", func(t *testing.T) {
+		type TestValuesPointers struct {
+			Name *string           `plain:"name"`
+			Sub  *TestData         `plain:"sub"`
+			Map  map[string]string `plain:"m"`
+		}
+
+		name := "x"
+		input := TestValuesPointers{Name: &name, Sub: &TestData{Name: "NY"}, Map: map[string]string{"a": "b"}}
+		values, err := ToValues(input)
+		if err != nil {
+			t.Fatalf("Was not expecting an error got %s", err)
+		}
+
+		var result TestValuesPointers
+		if err := FromValues(values, &result); err != nil {
+			t.Fatalf("Failed to read values: %v", err)
+		}
+		if !reflect.DeepEqual(result, input) {
+			t.Errorf("Round trip: got %+v, want %+v", result, input)
+		}
+	})
+
+	t.Run("Invalid value", func(t *testing.T) {
+		var result TestValues
+		if err := FromValues(url.Values{"age": {"abc"}}, &result); err == nil {
+			t.Fatal("Was expecting an error for an invalid int")
+		}
+	})
+
+	t.Run("Non pointer", func(t *testing.T) {
+		if err := FromValues(url.Values{}, TestValues{}); err == nil {
+			t.Fatal("Was expecting an error for a non pointer")
+		}
+	})
+}